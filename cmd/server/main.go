@@ -1,84 +1,224 @@
 package main
 
 import (
+	"LOIL-server/data"
 	"LOIL-server/internal/config"
 	"LOIL-server/internal/game"
 	"LOIL-server/internal/network"
 	worldpkg "LOIL-server/internal/world"
 	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 )
 
+// shutdownTimeout - сколько ждать отключения клиентов при остановке сервера
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Парсим флаги
 	serverAddr := flag.String("addr", ":8080", "Адрес WebSocket сервера")
 	headless := flag.Bool("headless", false, "Запуск без интерактивной консоли")
+	authSecret := flag.String("auth-secret", os.Getenv("LOIL_AUTH_SECRET"), "Секрет подписи токенов (по умолчанию $LOIL_AUTH_SECRET)")
+	accountsFile := flag.String("accounts", envOr("LOIL_ACCOUNTS_FILE", "data/accounts.json"), "Файл учетных записей игроков ($LOIL_ACCOUNTS_FILE)")
+	configDir := flag.String("config-dir", os.Getenv("LOIL_CONFIG_DIR"), "Каталог с JSON конфигурациями, переопределяющими встроенные ($LOIL_CONFIG_DIR)")
+	worldFile := flag.String("world", os.Getenv("LOIL_WORLD_FILE"), "Файл начального мира, по умолчанию встроенный ($LOIL_WORLD_FILE)")
+	saveDir := flag.String("save-dir", envOr("LOIL_SAVE_DIR", game.DefaultSaveDir), "Каталог сохранений ($LOIL_SAVE_DIR)")
+	autosave := flag.Duration("autosave", 5*time.Minute, "Интервал автосохранения (0 - только при выходе)")
+	backups := flag.Int("backups", game.DefaultSaveBackups, "Сколько резервных копий сохранения хранить")
+	sessionGrace := flag.Duration("session-grace", network.DefaultConfig().SessionGracePeriod, "Сколько персонаж ждет переподключения игрока (0 - выход сразу)")
+	viewRadius := flag.Float64("view-radius", network.DefaultConfig().ViewRadius, "Радиус видимости персонажа в клетках (0 - вся локация)")
+	seed := flag.Int64("seed", 0, "Начальное значение генератора случайных чисел (0 - случайное)")
+	spawnLocation := flag.Int("spawn-location", 0, "Локация возрождения погибших персонажей (0 - локация гибели)")
+	spawnX := flag.Int("spawn-x", -1, "Клетка возрождения (-1 - ближайшая к центру локации)")
+	inventorySize := flag.Int("inventory-size", game.DefaultInventorySize, "Число слотов инвентаря персонажа")
+	validateOnly := flag.Bool("validate", false, "Проверить конфигурации и мир, вывести отчет и выйти")
 	flag.Parse()
 
 	// Загружаем конфигурации
-	configs, err := config.LoadConfigs()
+	configs, err := config.LoadConfigs(*configDir)
 	if err != nil {
 		fmt.Printf("Ошибка загрузки конфигураций: %v\n", err)
-		return
+		os.Exit(1)
 	}
 
 	// Загружаем мир
-	world, err := worldpkg.LoadWorld("data/world.json", configs)
+	world, worldSource, err := loadWorld(*worldFile, filepath.Join(*saveDir, game.SaveFileName), configs)
 	if err != nil {
 		fmt.Printf("Ошибка загрузки мира: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Проверяем ссылки между конфигурациями и миром
+	report := configs.Validate()
+	worldpkg.Validate(world, worldSource, configs, report)
+	if *validateOnly {
+		report.Print(os.Stdout)
+		if report.HasErrors() {
+			os.Exit(1)
+		}
 		return
 	}
+	if report.HasErrors() {
+		report.Print(os.Stdout)
+		fmt.Println("Запуск отменен: исправьте ошибки в данных")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Мир загружен: %s\n", worldSource)
+
+	// Создаем игру
+	g := game.NewGame(world)
+	g.SaveDir = *saveDir
+	g.SaveBackups = *backups
+	g.Spawn = game.SpawnPoint{LocationID: *spawnLocation, X: *spawnX}
+	if *inventorySize > 0 {
+		g.InventorySize = *inventorySize
+	}
+	if *seed != 0 {
+		g.SetSeed(*seed)
+	}
+	fmt.Printf("Seed генератора: %d (повторить запуск: -seed %d)\n", g.Seed(), g.Seed())
+	g.Initialize()
+
+	// Запускаем автосохранение
+	persistence := game.NewPersistence(g, *autosave)
+	persistence.Start()
+
+	// По сигналу завершения останавливаем сервер и игровой цикл
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	if *headless {
 		// Серверный режим с сетью
-		runServerMode(world, *serverAddr)
+		serverConfig := network.DefaultConfig()
+		serverConfig.Addr = *serverAddr
+		serverConfig.UpdateInterval = 100 * time.Millisecond // 10 FPS
+		serverConfig.SessionGracePeriod = *sessionGrace
+		serverConfig.ViewRadius = *viewRadius
+		runServerMode(ctx, g, serverConfig, *authSecret, *accountsFile)
 	} else {
 		// Консольный режим для отладки
-		runConsoleMode(world)
+		runConsoleMode(ctx, g)
 	}
+
+	// Игровой цикл остановлен - сохраняем мир последний раз
+	persistence.Stop()
 }
 
-func runServerMode(w *worldpkg.World, addr string) {
-	fmt.Printf("Запуск сервера на %s...\n", addr)
+// loadWorld загружает мир: явно указанный файл, иначе последнее сохранение,
+// а если его нет - встроенный мир по умолчанию.
+// Возвращает также источник мира для сообщений об ошибках.
+func loadWorld(filename, saveFile string, configs *config.Configs) (*worldpkg.World, string, error) {
+	if filename != "" {
+		world, err := worldpkg.LoadWorld(filename, configs)
+		return world, filename, err
+	}
 
-	// Создаем игру
-	g := game.NewGame(w)
-	g.Initialize()
+	// Продолжаем с последнего сохранения
+	world, source, err := worldpkg.LoadSave(saveFile, configs)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return world, source, err
+	}
 
-	// Создаем мост между игрой и сетью
-	bridge := game.NewGameNetworkBridge(g)
+	// Сохранения нет - начинаем со встроенного мира
+	source = config.EmbeddedSource("world.json")
+	world, err = worldpkg.ParseWorld(data.DefaultWorld, configs)
+	if err != nil {
+		return nil, source, fmt.Errorf("%s: %w", source, err)
+	}
+	return world, source, nil
+}
 
-	// Настраиваем сервер
-	serverConfig := &network.ServerConfig{
-		Addr:           addr,
-		UpdateInterval: 100 * time.Millisecond, // 10 FPS
-		PingInterval:   30 * time.Second,
-		MaxMessageSize: 1024 * 10, // 10KB
+// envOr возвращает значение переменной окружения или значение по умолчанию
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
+	return def
+}
+
+func runServerMode(ctx context.Context, g *game.Game, serverConfig *network.ServerConfig, authSecret, accountsFile string) {
+	fmt.Printf("Запуск сервера на %s...\n", serverConfig.Addr)
+
+	// Создаем мост между игрой и сетью
+	bridge := game.NewGameNetworkBridge(g)
 
 	// Создаем и запускаем сервер
 	server := network.NewServer(bridge, serverConfig)
+	bridge.Attach(server)
+
+	// Настраиваем авторизацию
+	secret := []byte(authSecret)
+	if len(secret) == 0 {
+		generated, err := network.GenerateSecret()
+		if err != nil {
+			fmt.Printf("Ошибка генерации секрета: %v\n", err)
+			return
+		}
+		secret = generated
+		fmt.Println("Секрет токенов не задан, сгенерирован временный: токены станут недействительны после перезапуска")
+	}
+	server.Auth = network.NewHMACAuthenticator(secret, 24*time.Hour)
+	server.ChatFilters = append(server.ChatFilters, network.DuplicateFilter(10*time.Second))
+	g.RegisterMetrics(server.Metrics)
+
+	accounts, err := network.LoadAccountStore(accountsFile)
+	if err != nil {
+		fmt.Printf("Ошибка загрузки учетных записей: %v\n", err)
+		return
+	}
+	server.Accounts = accounts
 
 	// Запускаем игровой цикл в отдельной горутине
 	go g.RunGameLoop()
 
-	// Запускаем сервер (блокирующий вызов)
-	if err := server.Start(); err != nil {
-		fmt.Printf("Ошибка запуска сервера: %v\n", err)
+	// Запускаем сервер и ждем сигнала завершения или ошибки
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println("\nПолучен сигнал завершения")
+	case err := <-serverErr:
+		if err != nil {
+			fmt.Printf("Ошибка запуска сервера: %v\n", err)
+		}
+	}
+
+	// Уведомляем клиентов и закрываем соединения
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("Ошибка остановки сервера: %v\n", err)
 	}
+
+	// Клиенты отключены - останавливаем игровой цикл
+	g.Stop()
 }
 
-func runConsoleMode(w *worldpkg.World) {
+func runConsoleMode(ctx context.Context, g *game.Game) {
 	// Консольный режим без сети
-	g := game.NewGame(w)
-	g.Initialize()
-
 	go g.RunGameLoop()
-	runInputHandler(g)
+	go runInputHandler(g)
+
+	// Ждем команды exit или сигнала завершения
+	select {
+	case <-ctx.Done():
+		fmt.Println("\nПолучен сигнал завершения")
+	case <-g.Stopped():
+	}
+	g.Stop()
 
 	fmt.Println("Игра завершена.")
 }
@@ -91,7 +231,10 @@ func runInputHandler(g *game.Game) {
 	fmt.Println("         i - инвентарь, act - взаимодействия, x - состояние")
 	fmt.Println("         save - сохранить, exit - выход")
 
-	g.PrintState()
+	// Состояние читается в игровом цикле
+	g.Do(func(g *game.Game) {
+		g.PrintState()
+	})
 
 	for {
 		fmt.Print("\nВведите команду: ")
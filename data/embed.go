@@ -0,0 +1,9 @@
+// Package data содержит начальный мир, встроенный в бинарник сервера
+package data
+
+import _ "embed"
+
+// DefaultWorld - начальный мир по умолчанию (data/world.json)
+//
+//go:embed world.json
+var DefaultWorld []byte
@@ -1,26 +1,46 @@
 package config
 
 import (
+	"embed"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
 
-// InteractionResult - результат взаимодействия
+// defaultFiles - конфигурации по умолчанию, встроенные в бинарник
+//
+//go:embed *.json
+var defaultFiles embed.FS
+
+// InteractionResult - результат взаимодействия (строка таблицы добычи).
+// Количество задается либо фиксированным count, либо диапазоном min..max.
+// Строка с one_of не дает предмет сама, а выбирает один из вариантов по весу.
 type InteractionResult struct {
-	ItemID int `json:"item_id"`
-	Count  int `json:"count"`
+	ItemID    int                 `json:"item_id"`
+	Count     int                 `json:"count"`      // Фиксированное количество
+	Min       int                 `json:"min"`        // Минимальное количество (если задан max)
+	Max       int                 `json:"max"`        // Максимальное количество (0 - используется count)
+	Chance    float64             `json:"chance"`     // Вероятность выпадения 0..1 (0 - выпадает всегда)
+	Weight    int                 `json:"weight"`     // Вес варианта внутри one_of (по умолчанию 1)
+	OneOf     []InteractionResult `json:"one_of"`     // Варианты, из которых выпадает ровно один
+	Tool      string              `json:"tool"`       // Условие: у персонажа есть инструмент (hand, axe, ...)
+	MinGrowth int                 `json:"min_growth"` // Условие: стадия роста объекта не меньше
+	MaxGrowth int                 `json:"max_growth"` // Условие: стадия роста объекта не больше (0 - без ограничения)
 }
 
 // Interaction - взаимодействие с объектом
 type Interaction struct {
-	Type              string              `json:"type"`                // pick, chop, mine, harvest, collect, dig
-	Tool              string              `json:"tool"`                // hand, axe, pickaxe, knife, shovel
-	Time              int                 `json:"time"`                // Время в секундах
-	Results           []InteractionResult `json:"results"`             // Результаты
-	ReduceDurability  int                 `json:"reduce_durability"`   // Сколько прочности отнимать (по умолчанию 1)
-	TransformTo       int                 `json:"transform_to"`        // Во что превращается объект после взаимодействия
-	DestroyOnComplete bool                `json:"destroy_on_complete"` // Уничтожать ли объект после взаимодействия
+	Type              string              `json:"type"`                   // pick, chop, mine, harvest, collect, dig, loot
+	Tool              string              `json:"tool"`                   // hand, axe, pickaxe, knife, shovel
+	Time              int                 `json:"time"`                   // Время в секундах
+	Results           []InteractionResult `json:"results"`                // Результаты
+	ReduceDurability  int                 `json:"reduce_durability"`      // Сколько прочности отнимать (по умолчанию 1)
+	TransformTo       int                 `json:"transform_to"`           // Во что превращается объект после взаимодействия
+	DestroyOnComplete bool                `json:"destroy_on_complete"`    // Уничтожать ли объект после взаимодействия
+	TakeStorage       bool                `json:"take_storage,omitempty"` // Забрать содержимое хранилища объекта
 }
 
 // ObjectTypeConfig - конфигурация типа объекта
@@ -33,7 +53,9 @@ type ObjectTypeConfig struct {
 	Background    bool          `json:"background"`
 	Size          int           `json:"size"` // 1, 2, 3
 	MaxDurability int           `json:"max_durability"`
-	GrowthTime    int           `json:"growth_time"` // 0 для нерастущих
+	GrowthTime    int           `json:"growth_time"`         // 0 для нерастущих
+	GrowsInto     int           `json:"grows_into"`          // Во что превращается объект, когда вырастет (0 - остается собой)
+	Container     bool          `json:"container,omitempty"` // Хранилище для выпавших предметов (сумка на месте гибели)
 	Interactions  []Interaction `json:"interactions"`
 }
 
@@ -54,6 +76,7 @@ type GroundTypeConfig struct {
 	Walkable    bool   `json:"walkable"`
 	Buildable   bool   `json:"buildable"`
 	ResourceID  int    `json:"resource_id"`
+	Drinkable   bool   `json:"drinkable,omitempty"` // Из воды на этой клетке можно пить
 }
 
 // ItemTypeConfig - конфигурация типа предмета
@@ -61,24 +84,31 @@ type ItemTypeConfig struct {
 	ID          int     `json:"id"`
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
-	Type        string  `json:"type"` // food, resource, tool, seed
+	Type        string  `json:"type"`                // food, resource, tool, seed
+	ToolKind    string  `json:"tool_kind,omitempty"` // Для tool: axe, pickaxe, knife, shovel (ключ экипировки)
 	StackSize   int     `json:"stack_size"`
 	Weight      float64 `json:"weight"`
+	Nutrition   int     `json:"nutrition,omitempty"` // Насколько еда утоляет голод
+	Damage      int     `json:"damage,omitempty"`    // Урон при атаке экипированным предметом
 }
 
 // CreatureTypeConfig - конфигурация типа существа
 type CreatureTypeConfig struct {
-	ID              int      `json:"id"`
-	Name            string   `json:"name"`
-	Description     string   `json:"description"`
-	Type            string   `json:"type"` // humanoid, animal
-	Size            int      `json:"size"`
-	Health          int      `json:"health"`
-	Damage          int      `json:"damage"`
-	Speed           float64  `json:"speed"`
-	FavoriteFoods   []int    `json:"favorite_foods"`
-	Behaviors       []string `json:"behaviors"`
-	DefaultBehavior string   `json:"default_behavior"`
+	ID              int                 `json:"id"`
+	Name            string              `json:"name"`
+	Description     string              `json:"description"`
+	Type            string              `json:"type"` // humanoid, animal
+	Size            int                 `json:"size"`
+	Health          int                 `json:"health"`
+	Damage          int                 `json:"damage"`
+	Speed           float64             `json:"speed"`
+	FavoriteFoods   []int               `json:"favorite_foods"`
+	Behaviors       []string            `json:"behaviors"`
+	DefaultBehavior string              `json:"default_behavior"`
+	DetectRadius    float64             `json:"detect_radius,omitempty"`   // Радиус, в котором существо замечает персонажей (attack, flee)
+	AttackRange     float64             `json:"attack_range,omitempty"`    // Дистанция удара
+	AttackCooldown  float64             `json:"attack_cooldown,omitempty"` // Секунд между ударами
+	Loot            []InteractionResult `json:"loot,omitempty"`            // Что остается после гибели существа
 }
 
 // Configs - все конфигурации
@@ -88,63 +118,87 @@ type Configs struct {
 	GroundTypes   map[string]*GroundTypeConfig   `json:"ground_types"`
 	ItemTypes     map[string]*ItemTypeConfig     `json:"item_types"`
 	CreatureTypes map[string]*CreatureTypeConfig `json:"creature_types"`
+	Dir           string                         `json:"-"` // Каталог с файлами, переопределяющими встроенные
+	Sources       map[string]string              `json:"-"` // Имя файла -> откуда он загружен
 }
 
-// LoadConfigs загружает все конфигурации
-func LoadConfigs() (*Configs, error) {
+// LoadConfigs загружает все конфигурации.
+// Каждый файл из каталога dir переопределяет встроенную в бинарник версию;
+// если dir пуст или файла в нем нет, используется встроенная конфигурация.
+func LoadConfigs(dir string) (*Configs, error) {
 	configs := &Configs{
 		ObjectTypes:   make(map[string]*ObjectTypeConfig),
 		RoadTypes:     make(map[string]*RoadTypeConfig),
 		GroundTypes:   make(map[string]*GroundTypeConfig),
 		ItemTypes:     make(map[string]*ItemTypeConfig),
 		CreatureTypes: make(map[string]*CreatureTypeConfig),
+		Dir:           dir,
+		Sources:       make(map[string]string),
 	}
 
-	// Определяем путь к конфигурациям
-	configDir := "internal/config"
-
 	// Загружаем типы объектов
-	if err := loadJSON(filepath.Join(configDir, "object_types.json"), &configs.ObjectTypes); err != nil {
+	if err := configs.load("object_types.json", &configs.ObjectTypes, true); err != nil {
 		return nil, err
 	}
 
 	// Загружаем типы дорог
-	if err := loadJSON(filepath.Join(configDir, "road_types.json"), &configs.RoadTypes); err != nil {
+	if err := configs.load("road_types.json", &configs.RoadTypes, true); err != nil {
 		return nil, err
 	}
 
 	// Загружаем типы земли
-	if err := loadJSON(filepath.Join(configDir, "ground_types.json"), &configs.GroundTypes); err != nil {
+	if err := configs.load("ground_types.json", &configs.GroundTypes, true); err != nil {
 		return nil, err
 	}
 
 	// Загружаем типы предметов
-	itemTypesFile := filepath.Join(configDir, "item_types.json")
-	if _, err := os.Stat(itemTypesFile); err == nil {
-		if err := loadJSON(itemTypesFile, &configs.ItemTypes); err != nil {
-			return nil, err
-		}
-	} else {
-		configs.ItemTypes = make(map[string]*ItemTypeConfig)
+	if err := configs.load("item_types.json", &configs.ItemTypes, false); err != nil {
+		return nil, err
 	}
 
 	// Загружаем типы существ
-	creatureTypesFile := filepath.Join(configDir, "creature_types.json")
-	if _, err := os.Stat(creatureTypesFile); err == nil {
-		if err := loadJSON(creatureTypesFile, &configs.CreatureTypes); err != nil {
-			return nil, err
-		}
-	} else {
-		configs.CreatureTypes = make(map[string]*CreatureTypeConfig)
+	if err := configs.load("creature_types.json", &configs.CreatureTypes, false); err != nil {
+		return nil, err
 	}
 
 	return configs, nil
 }
 
-func loadJSON(filename string, target interface{}) error {
-	data, err := os.ReadFile(filename)
+// load читает файл конфигурации и запоминает, откуда он загружен
+func (c *Configs) load(name string, target interface{}, required bool) error {
+	data, source, err := readConfigFile(c.Dir, name)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, target)
+
+	c.Sources[name] = source
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	return nil
+}
+
+// readConfigFile читает файл из каталога переопределений, а если его там нет - из встроенных
+func readConfigFile(dir, name string) ([]byte, string, error) {
+	if dir != "" {
+		filename := filepath.Join(dir, name)
+		data, err := os.ReadFile(filename)
+		if err == nil {
+			return data, filename, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, filename, err
+		}
+	}
+
+	data, err := defaultFiles.ReadFile(name)
+	return data, EmbeddedSource(name), err
+}
+
+// EmbeddedSource возвращает обозначение встроенного в бинарник файла для сообщений и отчетов
+func EmbeddedSource(name string) string {
+	return "встроенный:" + name
 }
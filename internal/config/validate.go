@@ -0,0 +1,405 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// Известные типы предметов, инструментов и поведения существ
+var (
+	knownItemKinds = []string{"food", "resource", "tool", "seed"}
+	knownToolKinds = []string{"axe", "pickaxe", "knife", "shovel"}
+	knownBehaviors = []string{"walk", "wander", "rest", "eat", "attack", "flee"}
+)
+
+// HandTool - инструмент взаимодействий, выполняемых свободными руками
+const HandTool = "hand"
+
+// checkTool проверяет инструмент, требуемый взаимодействием или строкой добычи
+func checkTool(report *ValidationReport, file, path, tool string) {
+	if tool != HandTool && !slices.Contains(knownToolKinds, tool) {
+		report.Addf(file, path, "неизвестный инструмент %q (ожидается %s или один из %v)", tool, HandTool, knownToolKinds)
+	}
+}
+
+// Problem - ошибка в данных: файл, JSON путь внутри файла и описание
+type Problem struct {
+	File    string `json:"file"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.File, p.Path, p.Message)
+}
+
+// ValidationReport - все ошибки, найденные при проверке конфигов и мира
+type ValidationReport struct {
+	Problems []Problem `json:"problems"`
+}
+
+// Addf добавляет ошибку в отчет
+func (r *ValidationReport) Addf(file, path, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{
+		File:    file,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// HasErrors проверяет, найдены ли ошибки
+func (r *ValidationReport) HasErrors() bool {
+	return len(r.Problems) > 0
+}
+
+// Error реализует интерфейс error
+func (r *ValidationReport) Error() string {
+	return fmt.Sprintf("найдено ошибок в данных: %d", len(r.Problems))
+}
+
+// Print выводит отчет построчно
+func (r *ValidationReport) Print(w io.Writer) {
+	if !r.HasErrors() {
+		fmt.Fprintln(w, "Ошибок в данных не найдено")
+		return
+	}
+	for _, problem := range r.Problems {
+		fmt.Fprintln(w, problem.String())
+	}
+	fmt.Fprintln(w, r.Error())
+}
+
+// Validate проверяет конфигурации и ссылки между ними.
+// Возвращает отчет со всеми найденными ошибками (пустой, если ошибок нет).
+func (c *Configs) Validate() *ValidationReport {
+	report := &ValidationReport{}
+
+	c.validateObjectTypes(report)
+	c.validateRoadTypes(report)
+	c.validateGroundTypes(report)
+	c.validateItemTypes(report)
+	c.validateCreatureTypes(report)
+
+	return report
+}
+
+// HasObjectType проверяет, что тип объекта с таким ID существует
+func (c *Configs) HasObjectType(id int) bool {
+	for _, objType := range c.ObjectTypes {
+		if objType.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRoadType проверяет, что тип дороги с таким ID существует
+func (c *Configs) HasRoadType(id int) bool {
+	for _, roadType := range c.RoadTypes {
+		if roadType.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGroundType проверяет, что тип земли с таким ID существует
+func (c *Configs) HasGroundType(id int) bool {
+	for _, groundType := range c.GroundTypes {
+		if groundType.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// HasItemType проверяет, что тип предмета с таким ID существует
+func (c *Configs) HasItemType(id int) bool {
+	for _, itemType := range c.ItemTypes {
+		if itemType.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCreatureType проверяет, что тип существа с таким ID существует
+func (c *Configs) HasCreatureType(id int) bool {
+	for _, creatureType := range c.CreatureTypes {
+		if creatureType.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// file возвращает источник файла конфигурации для отчета
+func (c *Configs) file(name string) string {
+	if source, ok := c.Sources[name]; ok {
+		return source
+	}
+	return name
+}
+
+func (c *Configs) validateObjectTypes(report *ValidationReport) {
+	file := c.file("object_types.json")
+	ids := make(map[int]string)
+
+	for _, key := range sortedKeys(c.ObjectTypes) {
+		objType := c.ObjectTypes[key]
+		if objType == nil {
+			report.Addf(file, key, "пустое описание типа объекта")
+			continue
+		}
+
+		checkID(report, file, key, objType.ID, ids)
+
+		if objType.MaxDurability <= 0 {
+			report.Addf(file, key+".max_durability", "прочность должна быть положительной, получено %d", objType.MaxDurability)
+		}
+		if objType.GrowthTime < 0 {
+			report.Addf(file, key+".growth_time", "время роста не может быть отрицательным, получено %d", objType.GrowthTime)
+		}
+		if objType.GrowsInto != 0 {
+			switch {
+			case !c.HasObjectType(objType.GrowsInto):
+				report.Addf(file, key+".grows_into", "тип объекта %d не найден", objType.GrowsInto)
+			case objType.GrowsInto == objType.ID:
+				report.Addf(file, key+".grows_into", "объект не может вырасти сам в себя")
+			case objType.GrowthTime <= 0:
+				report.Addf(file, key+".grows_into", "задан grows_into, но growth_time равен %d", objType.GrowthTime)
+			}
+		}
+
+		for i, interaction := range objType.Interactions {
+			path := fmt.Sprintf("%s.interactions[%d]", key, i)
+			if interaction.Type == "" {
+				report.Addf(file, path+".type", "не указан тип взаимодействия")
+			}
+			if interaction.Tool == "" {
+				report.Addf(file, path+".tool", "не указан инструмент")
+			} else {
+				checkTool(report, file, path+".tool", interaction.Tool)
+			}
+			if interaction.Time < 0 {
+				report.Addf(file, path+".time", "время не может быть отрицательным, получено %d", interaction.Time)
+			}
+			if interaction.ReduceDurability < 0 {
+				report.Addf(file, path+".reduce_durability", "значение не может быть отрицательным, получено %d", interaction.ReduceDurability)
+			}
+			if interaction.TransformTo != 0 && !c.HasObjectType(interaction.TransformTo) {
+				report.Addf(file, path+".transform_to", "тип объекта %d не найден", interaction.TransformTo)
+			}
+			c.validateResults(report, file, path+".results", interaction.Results)
+		}
+	}
+}
+
+// validateResults проверяет таблицу добычи (включая вложенные группы one_of)
+func (c *Configs) validateResults(report *ValidationReport, file, path string, results []InteractionResult) {
+	for i, result := range results {
+		resultPath := fmt.Sprintf("%s[%d]", path, i)
+
+		if len(result.OneOf) > 0 {
+			if result.ItemID != 0 {
+				report.Addf(file, resultPath+".item_id", "строка с one_of не должна указывать item_id")
+			}
+			c.validateResults(report, file, resultPath+".one_of", result.OneOf)
+		} else if !c.HasItemType(result.ItemID) {
+			report.Addf(file, resultPath+".item_id", "тип предмета %d не найден в item_types.json", result.ItemID)
+		}
+
+		if result.Tool != "" {
+			checkTool(report, file, resultPath+".tool", result.Tool)
+		}
+		if result.Count < 0 {
+			report.Addf(file, resultPath+".count", "количество не может быть отрицательным, получено %d", result.Count)
+		}
+		if result.Min < 0 {
+			report.Addf(file, resultPath+".min", "количество не может быть отрицательным, получено %d", result.Min)
+		}
+		if result.Max > 0 && result.Min > result.Max {
+			report.Addf(file, resultPath+".min", "min (%d) больше max (%d)", result.Min, result.Max)
+		}
+		if result.Min > 0 && result.Max == 0 {
+			report.Addf(file, resultPath+".max", "задан min, но не задан max")
+		}
+		if result.Chance < 0 || result.Chance > 1 {
+			report.Addf(file, resultPath+".chance", "шанс должен быть от 0 до 1, получено %g", result.Chance)
+		}
+		if result.Weight < 0 {
+			report.Addf(file, resultPath+".weight", "вес не может быть отрицательным, получено %d", result.Weight)
+		}
+		if result.MinGrowth < 0 || result.MinGrowth > 100 {
+			report.Addf(file, resultPath+".min_growth", "стадия роста должна быть от 0 до 100, получено %d", result.MinGrowth)
+		}
+		if result.MaxGrowth < 0 || result.MaxGrowth > 100 {
+			report.Addf(file, resultPath+".max_growth", "стадия роста должна быть от 0 до 100, получено %d", result.MaxGrowth)
+		}
+		if result.MaxGrowth > 0 && result.MinGrowth > result.MaxGrowth {
+			report.Addf(file, resultPath+".min_growth", "min_growth (%d) больше max_growth (%d)", result.MinGrowth, result.MaxGrowth)
+		}
+	}
+}
+
+func (c *Configs) validateRoadTypes(report *ValidationReport) {
+	file := c.file("road_types.json")
+	ids := make(map[int]string)
+
+	for _, key := range sortedKeys(c.RoadTypes) {
+		roadType := c.RoadTypes[key]
+		if roadType == nil {
+			report.Addf(file, key, "пустое описание типа дороги")
+			continue
+		}
+
+		// -1 - особый тип "нет дороги"
+		if roadType.ID == 0 {
+			report.Addf(file, key+".id", "ID 0 зарезервирован для отсутствия дороги")
+		} else if other, ok := ids[roadType.ID]; ok {
+			report.Addf(file, key+".id", "ID %d уже используется в %s", roadType.ID, other)
+		} else {
+			ids[roadType.ID] = key
+		}
+
+		if roadType.SpeedMod < 0 {
+			report.Addf(file, key+".speed_mod", "модификатор скорости не может быть отрицательным, получено %g", roadType.SpeedMod)
+		}
+	}
+}
+
+func (c *Configs) validateGroundTypes(report *ValidationReport) {
+	file := c.file("ground_types.json")
+	ids := make(map[int]string)
+
+	for _, key := range sortedKeys(c.GroundTypes) {
+		groundType := c.GroundTypes[key]
+		if groundType == nil {
+			report.Addf(file, key, "пустое описание типа земли")
+			continue
+		}
+
+		checkID(report, file, key, groundType.ID, ids)
+
+		if groundType.ResourceID != 0 && !c.HasItemType(groundType.ResourceID) {
+			report.Addf(file, key+".resource_id", "тип предмета %d не найден в item_types.json", groundType.ResourceID)
+		}
+	}
+}
+
+func (c *Configs) validateItemTypes(report *ValidationReport) {
+	file := c.file("item_types.json")
+	ids := make(map[int]string)
+
+	for _, key := range sortedKeys(c.ItemTypes) {
+		itemType := c.ItemTypes[key]
+		if itemType == nil {
+			report.Addf(file, key, "пустое описание типа предмета")
+			continue
+		}
+
+		checkID(report, file, key, itemType.ID, ids)
+
+		if !slices.Contains(knownItemKinds, itemType.Type) {
+			report.Addf(file, key+".type", "неизвестный тип предмета %q", itemType.Type)
+		}
+		switch {
+		case itemType.Type == "tool" && itemType.ToolKind == "":
+			report.Addf(file, key+".tool_kind", "у инструмента не указан tool_kind")
+		case itemType.Type == "tool" && !slices.Contains(knownToolKinds, itemType.ToolKind):
+			report.Addf(file, key+".tool_kind", "неизвестный вид инструмента %q (ожидается один из %v)", itemType.ToolKind, knownToolKinds)
+		case itemType.Type != "tool" && itemType.ToolKind != "":
+			report.Addf(file, key+".tool_kind", "tool_kind задан для предмета типа %q, а не tool", itemType.Type)
+		}
+		if itemType.StackSize <= 0 {
+			report.Addf(file, key+".stack_size", "размер стопки должен быть положительным, получено %d", itemType.StackSize)
+		}
+		if itemType.Weight < 0 {
+			report.Addf(file, key+".weight", "вес не может быть отрицательным, получено %g", itemType.Weight)
+		}
+		if itemType.Nutrition < 0 {
+			report.Addf(file, key+".nutrition", "сытость не может быть отрицательной, получено %d", itemType.Nutrition)
+		} else if itemType.Nutrition > 0 && itemType.Type != "food" {
+			report.Addf(file, key+".nutrition", "сытость задана для предмета типа %q, а не food", itemType.Type)
+		}
+		if itemType.Damage < 0 {
+			report.Addf(file, key+".damage", "урон не может быть отрицательным, получено %d", itemType.Damage)
+		}
+	}
+}
+
+func (c *Configs) validateCreatureTypes(report *ValidationReport) {
+	file := c.file("creature_types.json")
+	ids := make(map[int]string)
+
+	for _, key := range sortedKeys(c.CreatureTypes) {
+		creatureType := c.CreatureTypes[key]
+		if creatureType == nil {
+			report.Addf(file, key, "пустое описание типа существа")
+			continue
+		}
+
+		checkID(report, file, key, creatureType.ID, ids)
+
+		if creatureType.Health <= 0 {
+			report.Addf(file, key+".health", "здоровье должно быть положительным, получено %d", creatureType.Health)
+		}
+		if creatureType.Speed < 0 {
+			report.Addf(file, key+".speed", "скорость не может быть отрицательной, получено %g", creatureType.Speed)
+		}
+		if creatureType.Damage < 0 {
+			report.Addf(file, key+".damage", "урон не может быть отрицательным, получено %d", creatureType.Damage)
+		}
+		if creatureType.DetectRadius < 0 {
+			report.Addf(file, key+".detect_radius", "радиус не может быть отрицательным, получено %g", creatureType.DetectRadius)
+		}
+		if creatureType.AttackRange < 0 {
+			report.Addf(file, key+".attack_range", "дистанция не может быть отрицательной, получено %g", creatureType.AttackRange)
+		}
+		if creatureType.AttackCooldown < 0 {
+			report.Addf(file, key+".attack_cooldown", "перезарядка не может быть отрицательной, получено %g", creatureType.AttackCooldown)
+		}
+		c.validateResults(report, file, key+".loot", creatureType.Loot)
+
+		// Любимая еда - это типы объектов, которые существо ест на месте
+		for i, foodID := range creatureType.FavoriteFoods {
+			if !c.HasObjectType(foodID) {
+				report.Addf(file, fmt.Sprintf("%s.favorite_foods[%d]", key, i), "тип объекта %d не найден в object_types.json", foodID)
+			}
+		}
+
+		for i, behavior := range creatureType.Behaviors {
+			if !slices.Contains(knownBehaviors, behavior) {
+				report.Addf(file, fmt.Sprintf("%s.behaviors[%d]", key, i), "неизвестное поведение %q", behavior)
+			}
+		}
+		if creatureType.DefaultBehavior != "" && !slices.Contains(creatureType.Behaviors, creatureType.DefaultBehavior) {
+			report.Addf(file, key+".default_behavior", "поведение %q отсутствует в behaviors", creatureType.DefaultBehavior)
+		}
+	}
+}
+
+// checkID проверяет, что ID положительный и не повторяется в файле
+func checkID(report *ValidationReport, file, key string, id int, ids map[int]string) {
+	if id <= 0 {
+		report.Addf(file, key+".id", "ID должен быть положительным, получено %d", id)
+		return
+	}
+	if other, ok := ids[id]; ok {
+		report.Addf(file, key+".id", "ID %d уже используется в %s", id, other)
+		return
+	}
+	ids[id] = key
+}
+
+// sortedKeys возвращает ключи map в отсортированном порядке (для стабильного отчета)
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
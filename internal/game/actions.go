@@ -0,0 +1,155 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"fmt"
+)
+
+// actionProgressInterval - как часто (в секундах) сообщать о прогрессе действия
+const actionProgressInterval = 0.5
+
+// Причины отмены действия
+const (
+	CancelReasonMoved      = "moved"
+	CancelReasonReplaced   = "replaced"
+	CancelReasonLeft       = "left"
+	CancelReasonTargetLost = "target_lost"
+	CancelReasonDied       = "died"
+)
+
+// ActionEvent - данные событий action_started, action_progress и action_completed
+type ActionEvent struct {
+	CharacterID      int
+	Type             string
+	ObjectID         int
+	InteractionIndex int
+	Duration         float64
+	Elapsed          float64
+	Cancelled        bool
+	Reason           string
+	Outcome          *InteractionOutcome // Результат (только для завершенного действия)
+}
+
+// StartInteraction начинает взаимодействие с объектом. number - номер действия среди
+// доступных персонажу взаимодействий объекта, как их показывает PrintAvailableInteractions;
+// так его передают и консольная команда act, и сетевой запрос interact.
+// Взаимодействия с нулевым временем выполняются сразу и возвращают результат.
+// Для длительных взаимодействий возвращается nil, а результат придет в событии action_completed.
+func (g *Game) StartInteraction(char *worldpkg.Character, objectID int, number int) *InteractionOutcome {
+	interaction, interactionIndex, failure := g.resolveAvailableInteraction(char, objectID, number)
+	if failure != nil {
+		fmt.Printf("%s: %s\n", char.Name, failure.Message)
+		return failure
+	}
+
+	// Новое действие отменяет предыдущее
+	if char.Action != nil {
+		g.CancelAction(char, CancelReasonReplaced)
+	}
+
+	if interaction.Time <= 0 {
+		return g.PerformInteraction(char, objectID, interaction)
+	}
+
+	// Персонаж останавливается, чтобы выполнить действие
+	char.Direction = 0
+	char.Vertical = 0
+
+	char.Action = &worldpkg.CharacterAction{
+		Type:             interaction.Type,
+		ObjectID:         objectID,
+		InteractionIndex: interactionIndex,
+		Duration:         float64(interaction.Time),
+	}
+
+	fmt.Printf("%s начал действие '%s' (%d с)\n", char.Name, interaction.Type, interaction.Time)
+	g.emitAction(EventActionStarted, char)
+
+	return nil
+}
+
+// CancelAction прерывает текущее действие персонажа
+func (g *Game) CancelAction(char *worldpkg.Character, reason string) {
+	if char.Action == nil {
+		return
+	}
+
+	fmt.Printf("%s прервал действие '%s' (%s)\n", char.Name, char.Action.Type, reason)
+
+	event := g.newActionEvent(char)
+	event.Cancelled = true
+	event.Reason = reason
+	char.Action = nil
+
+	g.emit(GameEvent{
+		Type:        EventActionCompleted,
+		CharacterID: char.ID,
+		LocationID:  char.Location,
+		Payload:     event,
+	})
+}
+
+// UpdateCharacterAction продвигает текущее действие персонажа
+func (g *Game) UpdateCharacterAction(char *worldpkg.Character, elapsed float64) {
+	action := char.Action
+	if action == nil {
+		return
+	}
+
+	// Движение прерывает действие
+	if char.Direction != 0 {
+		g.CancelAction(char, CancelReasonMoved)
+		return
+	}
+
+	action.Elapsed += elapsed
+	if action.Elapsed < action.Duration {
+		if action.Elapsed-action.LastReport >= actionProgressInterval {
+			action.LastReport = action.Elapsed
+			g.emitAction(EventActionProgress, char)
+		}
+		return
+	}
+
+	// Действие завершено - выполняем взаимодействие с повторной проверкой условий
+	action.Elapsed = action.Duration
+	outcome := g.PerformInteractionByIndex(char, action.ObjectID, action.InteractionIndex)
+
+	event := g.newActionEvent(char)
+	event.Outcome = outcome
+	if !outcome.Success {
+		event.Cancelled = true
+		event.Reason = CancelReasonTargetLost
+	}
+	char.Action = nil
+
+	g.emit(GameEvent{
+		Type:        EventActionCompleted,
+		CharacterID: char.ID,
+		LocationID:  char.Location,
+		Payload:     event,
+	})
+}
+
+// emitAction отправляет событие о текущем действии персонажа
+func (g *Game) emitAction(eventType EventType, char *worldpkg.Character) {
+	g.emit(GameEvent{
+		Type:        eventType,
+		CharacterID: char.ID,
+		LocationID:  char.Location,
+		Payload:     g.newActionEvent(char),
+	})
+}
+
+// newActionEvent создает данные события из текущего действия персонажа
+func (g *Game) newActionEvent(char *worldpkg.Character) *ActionEvent {
+	action := char.Action
+	return &ActionEvent{
+		CharacterID:      char.ID,
+		Type:             action.Type,
+		ObjectID:         action.ObjectID,
+		InteractionIndex: action.InteractionIndex,
+		Duration:         action.Duration,
+		Elapsed:          action.Elapsed,
+	}
+}
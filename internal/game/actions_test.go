@@ -0,0 +1,81 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"testing"
+)
+
+// testObjectOak - взрослый дуб: [0] chop (axe), [1] collect (hand)
+const testObjectOak = 8
+
+// newObjectGame ставит персонажа встроенного мира рядом с новым объектом типа typeID на свободном месте
+func newObjectGame(t *testing.T, typeID int) (*Game, *worldpkg.Character, int) {
+	t.Helper()
+
+	g, _ := newSimulation(t, 1)
+	char := g.GameWorld.Characters[0]
+	char.Direction = 0
+
+	road := g.State.LocationStates[char.Location].Road
+	for pos := 1; pos < len(road)-1; pos++ {
+		if g.GetObjectAtPosition(char.Location, pos-1) != nil ||
+			g.GetObjectAtPosition(char.Location, pos) != nil ||
+			g.GetObjectAtPosition(char.Location, pos+1) != nil {
+			continue
+		}
+		char.X = float64(pos)
+		obj := g.SpawnObject(typeID, char.Location, pos)
+		if obj == nil {
+			t.Fatalf("не удалось поставить объект типа %d", typeID)
+		}
+		return g, char, obj.ID
+	}
+	t.Fatal("нет свободного места для объекта")
+	return nil, nil, 0
+}
+
+func TestStartInteractionNumbersAvailableInteractions(t *testing.T) {
+	tests := []struct {
+		name        string
+		axe         bool
+		number      int
+		wantType    string // Начатое действие
+		wantIndex   int    // Индекс действия в конфиге объекта
+		wantFailure InteractionFailure
+	}{
+		{name: "голыми руками [0] - сбор", number: 0, wantType: "collect", wantIndex: 1},
+		{name: "с топором [0] - рубка", axe: true, number: 0, wantType: "chop", wantIndex: 0},
+		{name: "голыми руками [1] - нужен топор", number: 1, wantFailure: FailureToolRequired},
+		{name: "с топором [1] - руки заняты", axe: true, number: 1, wantFailure: FailureToolRequired},
+		{name: "номер вне действий объекта", number: 5, wantFailure: FailureInvalidInteraction},
+		{name: "отрицательный номер", number: -1, wantFailure: FailureInvalidInteraction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, char, oakID := newObjectGame(t, testObjectOak)
+			if tt.axe {
+				char.Equipped = map[string]int{"axe": testItemAxe}
+				char.HandsFree = false
+			}
+
+			outcome := g.StartInteraction(char, oakID, tt.number)
+			if tt.wantFailure != FailureNone {
+				if outcome == nil || outcome.Failure != tt.wantFailure {
+					t.Fatalf("результат %+v, ожидали неудачу %v", outcome, tt.wantFailure)
+				}
+				if char.Action != nil {
+					t.Errorf("начато действие %+v", char.Action)
+				}
+				return
+			}
+
+			if outcome != nil {
+				t.Fatalf("длительное действие вернуло результат сразу: %+v", outcome)
+			}
+			if char.Action == nil || char.Action.Type != tt.wantType || char.Action.InteractionIndex != tt.wantIndex {
+				t.Errorf("действие %+v, ожидали %s с индексом %d", char.Action, tt.wantType, tt.wantIndex)
+			}
+		})
+	}
+}
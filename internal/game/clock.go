@@ -0,0 +1,114 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// Параметры фиксированного шага симуляции
+const (
+	TickRate        = 60                     // Тиков симуляции в секунду
+	TickInterval    = time.Second / TickRate // Длительность одного тика
+	TickSeconds     = 1.0 / TickRate         // Длительность тика в секундах (шаг симуляции)
+	maxCatchUpTicks = 5                      // Сколько тиков цикл догоняет за раз, остальное отбрасывается
+)
+
+// Clock - источник времени игры. Игровой цикл берет из него время и тикер,
+// поэтому в тестах и при воспроизведении его можно заменить на ManualClock.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker - периодический сигнал часов
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock - системные часы (по умолчанию)
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+func (SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.ticker.C }
+
+func (t systemTicker) Stop() { t.ticker.Stop() }
+
+// ManualClock - часы, время которых двигается только через Advance
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*manualTicker
+}
+
+// NewManualClock создает часы, показывающие время start
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTicker{clock: c, period: d, next: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance переводит часы вперед на d и срабатывает тикеры, время которых наступило.
+// Как и time.Ticker, тикер не копит пропущенные срабатывания.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		if c.now.Before(t.next) {
+			continue
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+		for !c.now.Before(t.next) {
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+type manualTicker struct {
+	clock  *ManualClock
+	period time.Duration
+	next   time.Time
+	ch     chan time.Time
+}
+
+func (t *manualTicker) C() <-chan time.Time { return t.ch }
+
+func (t *manualTicker) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, other := range c.tickers {
+		if other == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package game
+
+import (
+	"LOIL-server/internal/config"
+	worldpkg "LOIL-server/internal/world"
+	"fmt"
+	"math"
+)
+
+// Параметры боя по умолчанию (если они не заданы в конфиге существа)
+const (
+	DefaultCharacterHealth = 100 // Здоровье нового персонажа
+
+	defaultDetectRadius   = 4.0 // Радиус, в котором существо замечает персонажей
+	defaultAttackRange    = 1.0 // Дистанция удара
+	defaultAttackCooldown = 2.0 // Секунд между ударами
+	chaseRadiusFactor     = 2.0 // Погоня прекращается, если цель дальше detect_radius * factor
+	fleeDistance          = 6   // На сколько клеток убегает существо
+
+	unarmedDamage           = 5   // Урон персонажа без оружия
+	characterAttackCooldown = 1.0 // Секунд между ударами персонажа
+)
+
+// Участники боя
+const (
+	CombatantCharacter = "character"
+	CombatantCreature  = "creature"
+)
+
+// CombatEvent - данные события combat: удар одного участника по другому
+type CombatEvent struct {
+	AttackerKind string
+	AttackerID   int
+	TargetKind   string
+	TargetID     int
+	Damage       int
+	Health       int // Здоровье цели после удара
+	MaxHealth    int
+	Killed       bool
+}
+
+// CreatureDeathEvent - данные события creature_died
+type CreatureDeathEvent struct {
+	CreatureID  int
+	TypeID      int
+	Name        string
+	X           float64
+	KillerKind  string
+	KillerID    int
+	ContainerID int // Хранилище с добычей (0 - ничего не выпало)
+}
+
+// initCharacterHealth задает здоровье и выносливость персонажам без них (старые сохранения и начальный мир)
+func initCharacterHealth(char *worldpkg.Character) {
+	if char.MaxHealth > 0 {
+		return
+	}
+	char.MaxHealth = DefaultCharacterHealth
+	char.Health = char.MaxHealth
+	char.Stamina = MaxVital
+}
+
+// detectRadius возвращает радиус, в котором существо замечает персонажей
+func detectRadius(cfg *config.CreatureTypeConfig) float64 {
+	if cfg.DetectRadius > 0 {
+		return cfg.DetectRadius
+	}
+	return defaultDetectRadius
+}
+
+// attackRange возвращает дистанцию удара существа
+func attackRange(cfg *config.CreatureTypeConfig) float64 {
+	if cfg.AttackRange > 0 {
+		return cfg.AttackRange
+	}
+	return defaultAttackRange
+}
+
+// attackCooldown возвращает перезарядку удара существа в секундах
+func attackCooldown(cfg *config.CreatureTypeConfig) float64 {
+	if cfg.AttackCooldown > 0 {
+		return cfg.AttackCooldown
+	}
+	return defaultAttackCooldown
+}
+
+// nearestCharacter возвращает ближайшего живого персонажа в локации существа не дальше radius
+func (g *Game) nearestCharacter(creature *worldpkg.Creature, radius float64) *worldpkg.Character {
+	var nearest *worldpkg.Character
+	best := radius
+	for _, char := range g.State.CharsByLocation[creature.Location] {
+		if char.Health <= 0 {
+			continue
+		}
+		if dist := math.Abs(char.X - creature.X); dist <= best {
+			nearest = char
+			best = dist
+		}
+	}
+	return nearest
+}
+
+// reactToCharacters переключает существо в атаку или бегство, если рядом персонаж.
+// Возвращает true, если поведение изменилось.
+func (g *Game) reactToCharacters(creature *worldpkg.Creature, cfg *config.CreatureTypeConfig) bool {
+	switch creature.CurrentBehavior.Type {
+	case "attack", "flee":
+		return false
+	}
+
+	canAttack := contains(cfg.Behaviors, "attack")
+	canFlee := contains(cfg.Behaviors, "flee")
+	if !canAttack && !canFlee {
+		return false
+	}
+
+	target := g.nearestCharacter(creature, detectRadius(cfg))
+	if target == nil {
+		return false
+	}
+
+	if canAttack {
+		g.startCreatureBehavior(creature, "attack", target.ID)
+		fmt.Printf("%s бросается на %s\n", creature.Name, target.Name)
+	} else {
+		g.startCreatureBehavior(creature, "flee", target.ID)
+		fmt.Printf("%s убегает от %s\n", creature.Name, target.Name)
+	}
+	return true
+}
+
+// startCreatureBehavior начинает поведение, направленное на персонажа targetID
+func (g *Game) startCreatureBehavior(creature *worldpkg.Creature, behaviorType string, targetID int) {
+	cooldown := 0.0
+	if creature.CurrentBehavior != nil {
+		// Перезарядка удара не сбрасывается сменой поведения
+		cooldown = creature.CurrentBehavior.Cooldown
+	}
+
+	creature.CurrentBehavior = &worldpkg.CreatureBehavior{
+		Type:      behaviorType,
+		TargetPos: -1,
+		TargetID:  targetID,
+		Duration:  g.GetBehaviorDuration(behaviorType),
+		Cooldown:  cooldown,
+	}
+}
+
+// ExecuteAttackBehavior преследует цель и бьет ее, когда она в пределах досягаемости
+func (g *Game) ExecuteAttackBehavior(creature *worldpkg.Creature, elapsed float64) {
+	cfg := g.GetCreatureConfig(creature.TypeID)
+	if cfg == nil {
+		return
+	}
+
+	target := g.GetCharacterByID(creature.CurrentBehavior.TargetID)
+	if target == nil || target.Health <= 0 || target.Location != creature.Location ||
+		math.Abs(target.X-creature.X) > detectRadius(cfg)*chaseRadiusFactor {
+		// Цель потеряна
+		g.ChooseNextBehavior(creature)
+		return
+	}
+
+	if math.Abs(target.X-creature.X) <= attackRange(cfg) {
+		if creature.CurrentBehavior.Cooldown <= 0 {
+			creature.CurrentBehavior.Cooldown = attackCooldown(cfg)
+			g.DamageCharacter(target, cfg.Damage, CombatantCreature, creature.ID)
+		}
+		return
+	}
+
+	// Догоняем цель
+	creature.CurrentBehavior.TargetPos = int(target.X + 0.5)
+	g.MoveCreatureToTarget(creature, elapsed)
+}
+
+// ExecuteFleeBehavior уводит существо от ближайшего персонажа
+func (g *Game) ExecuteFleeBehavior(creature *worldpkg.Creature, elapsed float64) {
+	cfg := g.GetCreatureConfig(creature.TypeID)
+	locState := g.State.LocationStates[creature.Location]
+	if cfg == nil || locState == nil {
+		return
+	}
+
+	threat := g.nearestCharacter(creature, detectRadius(cfg)*chaseRadiusFactor)
+	if threat == nil {
+		// Опасность миновала
+		g.ChooseNextBehavior(creature)
+		return
+	}
+
+	direction := 1
+	if threat.X > creature.X || (threat.X == creature.X && creature.X > float64(len(locState.Road))/2) {
+		direction = -1
+	}
+
+	currentPos := int(creature.X + 0.5)
+	targetPos := min(max(currentPos+direction*fleeDistance, 0), len(locState.Road)-1)
+	creature.CurrentBehavior.TargetID = threat.ID
+	creature.CurrentBehavior.TargetPos = targetPos
+	g.MoveCreatureToTarget(creature, elapsed)
+}
+
+// DamageCharacter наносит персонажу урон от участника attackerKind/attackerID
+func (g *Game) DamageCharacter(char *worldpkg.Character, damage int, attackerKind string, attackerID int) {
+	if char.Health <= 0 || damage <= 0 {
+		return
+	}
+
+	char.Health = max(0, char.Health-damage)
+	killed := char.Health == 0
+
+	fmt.Printf("%s получает %d урона, здоровье %d/%d\n", char.Name, damage, char.Health, char.MaxHealth)
+	g.emit(GameEvent{
+		Type:        EventCombat,
+		CharacterID: char.ID,
+		LocationID:  char.Location,
+		Payload: &CombatEvent{
+			AttackerKind: attackerKind,
+			AttackerID:   attackerID,
+			TargetKind:   CombatantCharacter,
+			TargetID:     char.ID,
+			Damage:       damage,
+			Health:       char.Health,
+			MaxHealth:    char.MaxHealth,
+			Killed:       killed,
+		},
+	})
+
+	if killed {
+		g.KillCharacter(char, attackerKind, attackerID)
+	}
+}
+
+// DamageCreature наносит существу урон. Существо со здоровьем 0 погибает и удаляется из мира.
+// Раненое существо убегает от персонажа или нападает на него, если умеет.
+// Возвращает данные о гибели или nil, если существо выжило.
+func (g *Game) DamageCreature(creature *worldpkg.Creature, damage int, attackerKind string, attackerID int) *CreatureDeathEvent {
+	if creature.Health <= 0 || damage <= 0 {
+		return nil
+	}
+
+	creature.Health = max(0, creature.Health-damage)
+	killed := creature.Health == 0
+
+	g.emit(GameEvent{
+		Type:       EventCombat,
+		LocationID: creature.Location,
+		Payload: &CombatEvent{
+			AttackerKind: attackerKind,
+			AttackerID:   attackerID,
+			TargetKind:   CombatantCreature,
+			TargetID:     creature.ID,
+			Damage:       damage,
+			Health:       creature.Health,
+			MaxHealth:    creature.MaxHealth,
+			Killed:       killed,
+		},
+	})
+
+	if killed {
+		return g.KillCreature(creature, attackerKind, attackerID)
+	}
+
+	if attackerKind == CombatantCharacter {
+		if cfg := g.GetCreatureConfig(creature.TypeID); cfg != nil {
+			switch {
+			case contains(cfg.Behaviors, "attack"):
+				g.startCreatureBehavior(creature, "attack", attackerID)
+			case contains(cfg.Behaviors, "flee"):
+				g.startCreatureBehavior(creature, "flee", attackerID)
+			}
+		}
+	}
+	return nil
+}
+
+// KillCreature удаляет погибшее существо, оставляет на его месте хранилище с добычей
+// из таблицы loot и сообщает о смерти
+func (g *Game) KillCreature(creature *worldpkg.Creature, killerKind string, killerID int) *CreatureDeathEvent {
+	fmt.Printf("%s погибает\n", creature.Name)
+
+	event := &CreatureDeathEvent{
+		CreatureID: creature.ID,
+		TypeID:     creature.TypeID,
+		Name:       creature.Name,
+		X:          creature.X,
+		KillerKind: killerKind,
+		KillerID:   killerID,
+	}
+	locationID := creature.Location
+
+	// Условия добычи (например, нужный инструмент) проверяются у убившего персонажа
+	var killer *worldpkg.Character
+	if killerKind == CombatantCharacter {
+		killer = g.GetCharacterByID(killerID)
+	}
+
+	g.RemoveCreature(creature.ID)
+
+	if cfg := g.GetCreatureConfig(creature.TypeID); cfg != nil {
+		if container := g.DropContainer(locationID, creature.X, g.RollResults(killer, nil, cfg.Loot)); container != nil {
+			event.ContainerID = container.ID
+		}
+	}
+
+	g.emit(GameEvent{
+		Type:       EventCreatureDied,
+		LocationID: locationID,
+		Payload:    event,
+	})
+	return event
+}
+
+// weaponDamage возвращает урон лучшего экипированного оружия персонажа
+func (g *Game) weaponDamage(char *worldpkg.Character) int {
+	damage := unarmedDamage
+	for _, itemID := range char.Equipped {
+		if itemConfig := g.GetItemConfig(itemID); itemConfig != nil {
+			damage = max(damage, itemConfig.Damage)
+		}
+	}
+	return damage
+}
+
+// AttackCreature наносит удар существу creatureID, стоящему на клетке персонажа или соседней.
+// Урон определяется экипированным оружием, удары не чаще characterAttackCooldown.
+func (g *Game) AttackCreature(char *worldpkg.Character, creatureID int) *InteractionOutcome {
+	outcome := &InteractionOutcome{
+		Interaction: "attack",
+		CreatureID:  creatureID,
+	}
+
+	creature := g.GetCreatureByID(creatureID)
+	if creature == nil || creature.Location != char.Location {
+		return outcome.fail(FailureCreatureNotFound, fmt.Sprintf("Существо с ID %d не найдено", creatureID))
+	}
+
+	pos := int(char.X + 0.5)
+	creaturePos := int(creature.X + 0.5)
+	if creaturePos < pos-1 || creaturePos > pos+1 {
+		return outcome.fail(FailureOutOfReach, fmt.Sprintf("%s слишком далеко", creature.Name))
+	}
+
+	if char.AttackTick > 0 && g.GameWorld.Tick-char.AttackTick < uint64(secondsToTicks(characterAttackCooldown)) {
+		return outcome.fail(FailureCooldown, "Рано для следующего удара")
+	}
+
+	// Удар прерывает текущее действие
+	if char.Action != nil {
+		g.CancelAction(char, CancelReasonReplaced)
+	}
+	char.AttackTick = g.GameWorld.Tick
+
+	damage := g.weaponDamage(char)
+	fmt.Printf("%s бьет %s (%d урона)\n", char.Name, creature.Name, damage)
+
+	outcome.Success = true
+	outcome.Damage = damage
+	outcome.Creature = creature
+	if death := g.DamageCreature(creature, damage, CombatantCharacter, char.ID); death != nil {
+		outcome.Killed = true
+		outcome.ContainerID = death.ContainerID
+		outcome.Message = fmt.Sprintf("%s убит", creature.Name)
+	} else {
+		outcome.Message = fmt.Sprintf("Нанесено %d урона, у %s осталось %d/%d", damage, creature.Name, creature.Health, creature.MaxHealth)
+	}
+	return outcome
+}
+
+// PrintCreaturesInReach выводит существ, которых персонаж может ударить
+func (g *Game) PrintCreaturesInReach(char *worldpkg.Character) {
+	pos := int(char.X + 0.5)
+	found := false
+	for _, creature := range g.State.CreaturesByLocation[char.Location] {
+		creaturePos := int(creature.X + 0.5)
+		if creaturePos < pos-1 || creaturePos > pos+1 {
+			continue
+		}
+		if !found {
+			fmt.Printf("\nСущества рядом (урон %d):\n", g.weaponDamage(char))
+			found = true
+		}
+		fmt.Printf("  %s (ID: %d) поз: %d, здоровье: %d/%d\n", creature.Name, creature.ID, creaturePos, creature.Health, creature.MaxHealth)
+	}
+	if found {
+		fmt.Println("Для атаки введите: attack <ID существа>")
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import "errors"
+
+// ErrGameStopped - игровой цикл остановлен и не принимает команды
+var ErrGameStopped = errors.New("игра остановлена")
+
+// Command - действие, выполняемое внутри игрового цикла.
+// Все изменения состояния игры из других горутин должны проходить через команды,
+// чтобы у симуляции был единственный писатель.
+type Command func(g *Game)
+
+// Enqueue ставит команду в очередь игрового цикла без ожидания выполнения.
+// Возвращает false, если игровой цикл уже остановлен.
+func (g *Game) Enqueue(cmd Command) bool {
+	select {
+	case g.CommandChan <- cmd:
+		return true
+	case <-g.stopped:
+		return false
+	}
+}
+
+// Do выполняет команду в игровом цикле и ждет ее завершения.
+// Возвращает false, если игровой цикл остановлен и команда не была выполнена.
+func (g *Game) Do(cmd Command) bool {
+	done := make(chan struct{})
+	if !g.Enqueue(func(g *Game) {
+		cmd(g)
+		close(done)
+	}) {
+		return false
+	}
+
+	select {
+	case <-done:
+		return true
+	case <-g.stopped:
+		return false
+	}
+}
+
+// Stop останавливает игровой цикл и ждет его завершения.
+// Вызывается только после запуска RunGameLoop; повторный вызов безопасен.
+func (g *Game) Stop() {
+	select {
+	case g.ExitChan <- true:
+	case <-g.stopped:
+	}
+	<-g.stopped
+}
+
+// Stopped возвращает канал, который закрывается при завершении игрового цикла
+func (g *Game) Stopped() <-chan struct{} {
+	return g.stopped
+}
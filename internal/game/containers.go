@@ -0,0 +1,117 @@
+package game
+
+import (
+	"LOIL-server/internal/config"
+	worldpkg "LOIL-server/internal/world"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// containerType возвращает тип объекта-хранилища ("container": true) с наименьшим ID
+func (g *Game) containerType() *config.ObjectTypeConfig {
+	for _, id := range slices.Sorted(maps.Keys(g.Registries.ObjectTypeByID)) {
+		if objType := g.Registries.ObjectTypeByID[id]; objType.Container {
+			return objType
+		}
+	}
+	return nil
+}
+
+// nextObjectID возвращает свободный ID для нового объекта
+func (g *Game) nextObjectID() int {
+	maxID := 0
+	for id := range g.GameWorld.Objects {
+		maxID = max(maxID, id)
+	}
+	return maxID + 1
+}
+
+// freeCellNear ищет ближайшую к pos проходимую клетку без объекта.
+// Если такой нет, возвращает pos.
+func (g *Game) freeCellNear(locationID int, pos int) int {
+	locState := g.State.LocationStates[locationID]
+	if locState == nil {
+		return pos
+	}
+
+	for offset := 0; offset < len(locState.Road); offset++ {
+		for _, cell := range []int{pos - offset, pos + offset} {
+			if g.IsPositionWalkable(locationID, cell) && g.GetObjectAtPosition(locationID, cell) == nil {
+				return cell
+			}
+		}
+	}
+	return pos
+}
+
+// SpawnObject создает объект типа typeID на клетке x локации
+func (g *Game) SpawnObject(typeID int, locationID int, x int) *worldpkg.WorldObject {
+	objConfig := g.GetObjectConfig(typeID)
+	if objConfig == nil || g.State.LocationStates[locationID] == nil {
+		return nil
+	}
+
+	obj := &worldpkg.WorldObject{
+		ID:         g.nextObjectID(),
+		TypeID:     typeID,
+		X:          x,
+		LocationID: locationID,
+		Durability: objConfig.MaxDurability,
+		Storage:    make(map[int]int),
+		CustomData: make(map[string]interface{}),
+	}
+	g.initGrowth(obj)
+
+	if g.GameWorld.Objects == nil {
+		g.GameWorld.Objects = make(map[int]*worldpkg.WorldObject)
+	}
+	g.GameWorld.Objects[obj.ID] = obj
+	g.State.ObjectsByLocation[locationID] = append(g.State.ObjectsByLocation[locationID], obj)
+	g.UpdateObjectLayer(locationID, x, 0, typeID)
+
+	return obj
+}
+
+// DropContainer кладет предметы в новое хранилище рядом с позицией x.
+// Возвращает nil, если предметов нет или тип хранилища не настроен.
+func (g *Game) DropContainer(locationID int, x float64, items []worldpkg.InventoryItem) *worldpkg.WorldObject {
+	if len(items) == 0 {
+		return nil
+	}
+
+	containerType := g.containerType()
+	if containerType == nil {
+		fmt.Println("Тип объекта-хранилища не настроен, предметы потеряны")
+		return nil
+	}
+
+	obj := g.SpawnObject(containerType.ID, locationID, g.freeCellNear(locationID, int(x+0.5)))
+	if obj == nil {
+		return nil
+	}
+	for _, item := range items {
+		if item.Count > 0 {
+			obj.Storage[item.ItemID] += item.Count
+		}
+	}
+
+	fmt.Printf("%s (ID: %d) - выпавшие предметы на клетке %d локации %d\n", containerType.Name, obj.ID, obj.X, locationID)
+	return obj
+}
+
+// takeStorage перекладывает содержимое хранилища в инвентарь персонажа.
+// Предметы, которые не поместились, остаются в хранилище.
+func (g *Game) takeStorage(char *worldpkg.Character, obj *worldpkg.WorldObject) []worldpkg.InventoryItem {
+	var taken []worldpkg.InventoryItem
+	for _, itemID := range slices.Sorted(maps.Keys(obj.Storage)) {
+		count := obj.Storage[itemID]
+		if added := g.AddToInventory(char, itemID, count); added > 0 {
+			taken = append(taken, worldpkg.InventoryItem{ItemID: itemID, Count: added})
+			if obj.Storage[itemID] = count - added; obj.Storage[itemID] <= 0 {
+				delete(obj.Storage, itemID)
+			}
+		}
+	}
+	return taken
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"LOIL-server/data"
+	worldpkg "LOIL-server/internal/world"
+	"bytes"
+	"testing"
+	"time"
+)
+
+// testStart - время начала симуляции в тестах
+var testStart = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newSimulation создает игру со встроенным миром, ручными часами и заданным seed
+func newSimulation(t *testing.T, seed int64) (*Game, *ManualClock) {
+	t.Helper()
+
+	world, err := worldpkg.ParseWorld(data.DefaultWorld, testConfigs(t))
+	if err != nil {
+		t.Fatalf("ParseWorld: %v", err)
+	}
+
+	clock := NewManualClock(testStart)
+	g := NewGame(world)
+	g.Clock = clock
+	g.SetSeed(seed)
+	g.Initialize()
+
+	// Персонаж идет вправо, чтобы в симуляции участвовало движение
+	if len(world.Characters) > 0 {
+		world.Characters[0].Direction = 1
+	}
+	return g, clock
+}
+
+// runSimulation выполняет ticks тиков и возвращает сохранение мира
+func runSimulation(t *testing.T, g *Game, clock *ManualClock, ticks int) []byte {
+	t.Helper()
+
+	for i := 0; i < ticks; i++ {
+		g.Step()
+		clock.Advance(TickInterval)
+	}
+
+	save, err := g.MarshalSave()
+	if err != nil {
+		t.Fatalf("MarshalSave: %v", err)
+	}
+	return save
+}
+
+func TestStepDeterministic(t *testing.T) {
+	const ticks = 60 * TickRate // Минута игрового времени
+
+	a, clockA := newSimulation(t, 42)
+	b, clockB := newSimulation(t, 42)
+	initial, err := a.MarshalSave()
+	if err != nil {
+		t.Fatalf("MarshalSave: %v", err)
+	}
+
+	saveA := runSimulation(t, a, clockA, ticks)
+	saveB := runSimulation(t, b, clockB, ticks)
+
+	if a.Tick() != ticks {
+		t.Errorf("выполнено тиков %d, ожидали %d", a.Tick(), ticks)
+	}
+	if bytes.Equal(saveA, initial) {
+		t.Fatal("мир не изменился за время симуляции")
+	}
+	if !bytes.Equal(saveA, saveB) {
+		t.Errorf("одинаковые seed и мир дали разные сохранения:\n%s\n---\n%s", saveA, saveB)
+	}
+
+	// Другой seed меняет поведение существ, иначе тест ничего не проверяет
+	c, clockC := newSimulation(t, 43)
+	if bytes.Equal(saveA, runSimulation(t, c, clockC, ticks)) {
+		t.Error("разные seed дали одинаковые сохранения")
+	}
+}
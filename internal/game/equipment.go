@@ -0,0 +1,124 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Ошибки экипировки
+var (
+	ErrNotTool         = errors.New("предмет не является инструментом")
+	ErrNothingEquipped = errors.New("в руках ничего нет")
+	ErrInventoryFull   = errors.New("в инвентаре нет места")
+)
+
+// CancelReasonUnequipped - действие прервано сменой инструмента в руках
+const CancelReasonUnequipped = "unequipped"
+
+// Equip берет в руки инструмент из слота инвентаря.
+// В руках помещается один инструмент: прежний возвращается в инвентарь.
+func (g *Game) Equip(char *worldpkg.Character, slot int) error {
+	if !g.validSlot(slot) {
+		return ErrInvalidSlot
+	}
+	item, ok := char.Inventory[slot]
+	if !ok {
+		return ErrEmptySlot
+	}
+	itemConfig := g.GetItemConfig(item.ItemID)
+	if itemConfig == nil || itemConfig.Type != "tool" || itemConfig.ToolKind == "" {
+		return ErrNotTool
+	}
+
+	// Прежний инструмент займет освободившийся слот, а если в слоте стопка - нужно другое место
+	if item.Count > 1 && !g.hasRoomFor(char, char.Equipped) {
+		return ErrInventoryFull
+	}
+
+	g.takeFromSlot(char, slot, 1)
+	if len(char.Equipped) > 0 {
+		if err := g.Unequip(char, ""); err != nil {
+			// Прежние инструменты некуда убрать - возвращаем новый на место
+			g.AddToInventory(char, item.ItemID, 1)
+			return err
+		}
+	}
+
+	if char.Equipped == nil {
+		char.Equipped = make(map[string]int)
+	}
+	char.Equipped[itemConfig.ToolKind] = item.ItemID
+	char.HandsFree = false
+	g.cancelForEquipment(char)
+	g.inventoryChanged(char)
+
+	fmt.Printf("%s берет в руки %s\n", char.Name, itemConfig.Name)
+	return nil
+}
+
+// Unequip убирает инструмент вида toolKind в инвентарь (пустой toolKind - все инструменты)
+func (g *Game) Unequip(char *worldpkg.Character, toolKind string) error {
+	kinds := slices.Sorted(maps.Keys(char.Equipped))
+	if toolKind != "" {
+		if _, ok := char.Equipped[toolKind]; !ok {
+			return ErrNothingEquipped
+		}
+		kinds = []string{toolKind}
+	}
+	if len(kinds) == 0 {
+		return ErrNothingEquipped
+	}
+
+	unequipped := make(map[string]int, len(kinds))
+	for _, kind := range kinds {
+		unequipped[kind] = char.Equipped[kind]
+	}
+	if !g.hasRoomFor(char, unequipped) {
+		return ErrInventoryFull
+	}
+
+	for _, kind := range kinds {
+		itemID := char.Equipped[kind]
+		delete(char.Equipped, kind)
+		g.AddToInventory(char, itemID, 1)
+
+		if itemConfig := g.GetItemConfig(itemID); itemConfig != nil {
+			fmt.Printf("%s убирает %s в инвентарь\n", char.Name, itemConfig.Name)
+		}
+	}
+	char.HandsFree = len(char.Equipped) == 0
+	g.cancelForEquipment(char)
+	g.inventoryChanged(char)
+	return nil
+}
+
+// hasRoomFor проверяет, поместятся ли инструменты в инвентарь
+func (g *Game) hasRoomFor(char *worldpkg.Character, tools map[string]int) bool {
+	free := g.InventorySize - len(char.Inventory)
+	for _, itemID := range tools {
+		fits := false
+		for _, item := range char.Inventory {
+			if item.ItemID == itemID && item.Count < g.stackSize(itemID) {
+				fits = true
+				break
+			}
+		}
+		if !fits {
+			if free == 0 {
+				return false
+			}
+			free--
+		}
+	}
+	return true
+}
+
+// cancelForEquipment прерывает текущее действие: оно могло зависеть от инструмента в руках
+func (g *Game) cancelForEquipment(char *worldpkg.Character) {
+	if char.Action != nil {
+		g.CancelAction(char, CancelReasonUnequipped)
+	}
+}
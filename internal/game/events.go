@@ -0,0 +1,30 @@
+package game
+
+// EventType - тип игрового события
+type EventType string
+
+const (
+	EventActionStarted    EventType = "action_started"
+	EventActionProgress   EventType = "action_progress"
+	EventActionCompleted  EventType = "action_completed"
+	EventCombat           EventType = "combat"
+	EventCreatureDied     EventType = "creature_died"
+	EventCharacterDied    EventType = "character_died"
+	EventInventoryChanged EventType = "inventory_changed"
+)
+
+// GameEvent - событие игры, адресованное клиентам.
+// Payload содержит данные события, его тип зависит от Type.
+type GameEvent struct {
+	Type        EventType
+	CharacterID int // Персонаж, к которому относится событие (0 - нет)
+	LocationID  int
+	Payload     interface{}
+}
+
+// emit передает событие получателю, если он установлен
+func (g *Game) emit(event GameEvent) {
+	if g.OnEvent != nil {
+		g.OnEvent(event)
+	}
+}
@@ -2,23 +2,51 @@ package game
 
 import (
 	"LOIL-server/internal/config"
+	"LOIL-server/internal/metrics"
 	worldpkg "LOIL-server/internal/world"
 	"fmt"
+	"math"
 	"math/rand"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Game struct {
-	GameWorld  *worldpkg.World
-	State      *GameState
-	Registries *worldpkg.Registries
-	ExitChan   chan bool
-	UpdateChan chan bool
-	InputChan  chan string
-	rand       *rand.Rand // Локальный генератор случайных чисел
+	GameWorld     *worldpkg.World
+	State         *GameState
+	Registries    *worldpkg.Registries
+	ExitChan      chan bool
+	UpdateChan    chan bool
+	InputChan     chan string
+	CommandChan   chan Command // Команды от сетевых горутин
+	Players       *PlayerRegistry
+	SaveDir       string                   // Каталог сохранений
+	SaveBackups   int                      // Сколько резервных копий сохранения хранить
+	OnEvent       func(event GameEvent)    // Получатель игровых событий (вызывается из игрового цикла)
+	Clock         Clock                    // Источник времени игрового цикла (задается до Initialize)
+	Spawn         SpawnPoint               // Где персонажи появляются после гибели
+	InventorySize int                      // Число слотов инвентаря персонажа
+	rand          *rand.Rand               // Локальный генератор случайных чисел
+	seed          int64                    // Начальное значение генератора (см. SetSeed)
+	snapshot      atomic.Pointer[Snapshot] // Последний снимок мира для читателей
+	stopped       chan struct{}            // Закрывается при завершении игрового цикла
+	started       atomic.Bool              // Игровой цикл запущен (RunGameLoop)
+
+	tickDuration *metrics.Histogram // Длительность обработки тиков
+
+	inventoryDirty map[int]bool // Персонажи, об изменении инвентаря которых еще не сообщено
+
+	growthElapsed float64    // Время, накопленное с последнего пересчета роста объектов
+	saveMu        sync.Mutex // Сериализует запись сохранений на диск
 }
 
+// DefaultSaveDir - каталог сохранений по умолчанию
+const DefaultSaveDir = "data/save"
+
+// NewGame создает игру для мира w. Генератор случайных чисел получает seed из текущего
+// времени, поэтому для воспроизводимой симуляции до Initialize нужно вызвать SetSeed.
 func NewGame(w *worldpkg.World) *Game {
 	// Создаем реестры из конфигов
 	registries := worldpkg.NewRegistries(w.Configs)
@@ -31,19 +59,29 @@ func NewGame(w *worldpkg.World) *Game {
 		Running:             true,
 	}
 
-	// Создаем локальный генератор случайных чисел
-	source := rand.NewSource(time.Now().UnixNano())
-	random := rand.New(source)
-
-	return &Game{
-		GameWorld:  w,
-		State:      state,
-		Registries: registries,
-		ExitChan:   make(chan bool),
-		UpdateChan: make(chan bool, 100),
-		InputChan:  make(chan string, 10),
-		rand:       random,
+	g := &Game{
+		GameWorld:     w,
+		State:         state,
+		Registries:    registries,
+		ExitChan:      make(chan bool),
+		UpdateChan:    make(chan bool, 100),
+		InputChan:     make(chan string, 10),
+		CommandChan:   make(chan Command, 256),
+		Players:       NewPlayerRegistry(),
+		SaveDir:       DefaultSaveDir,
+		SaveBackups:   DefaultSaveBackups,
+		Clock:         SystemClock{},
+		Spawn:         SpawnPoint{X: -1},
+		InventorySize: DefaultInventorySize,
+		stopped:       make(chan struct{}),
+
+		tickDuration:   newTickDuration(),
+		inventoryDirty: make(map[int]bool),
 	}
+
+	// Создаем локальный генератор случайных чисел
+	g.SetSeed(time.Now().UnixNano())
+	return g
 }
 
 // RandomInt возвращает случайное целое число в диапазоне [min, max]
@@ -89,16 +127,22 @@ func (g *Game) Initialize() {
 
 	// Распределяем персонажей по локациям (только в список, не в слой)
 	for _, char := range g.GameWorld.Characters {
+		initCharacterHealth(char)
+		char.HandsFree = len(char.Equipped) == 0
 		g.State.CharsByLocation[char.Location] = append(g.State.CharsByLocation[char.Location], char)
 	}
 
 	// Распределяем объекты по локациям
 	for _, obj := range g.GameWorld.Objects {
+		g.initGrowth(obj)
 		if obj.LocationID > 0 {
 			g.State.ObjectsByLocation[obj.LocationID] = append(g.State.ObjectsByLocation[obj.LocationID], obj)
 		}
 	}
 
+	// Учитываем рост объектов за время, пока сервер не работал
+	g.catchUpGrowth(g.Clock.Now())
+
 	// Распределяем существ по локациям (только в список)
 	for _, creature := range g.GameWorld.Creatures {
 		g.State.CreaturesByLocation[creature.Location] = append(g.State.CreaturesByLocation[creature.Location], creature)
@@ -114,8 +158,11 @@ func (g *Game) Initialize() {
 	// Инициализируем начальное поведение существ
 	for _, creature := range g.GameWorld.Creatures {
 		g.SetDefaultBehavior(creature)
-		creature.LastUpdate = time.Now()
+		creature.LastUpdate = g.Clock.Now()
 	}
+
+	// Публикуем начальный снимок мира для сетевых читателей
+	g.publishSnapshot()
 }
 
 // GetObjectAtPosition возвращает объект на позиции
@@ -247,13 +294,17 @@ func (g *Game) GetAvailableInteractions(char *worldpkg.Character) []config.Inter
 
 // CanPerformInteraction проверяет, может ли персонаж выполнить взаимодействие
 func (g *Game) CanPerformInteraction(char *worldpkg.Character, interaction config.Interaction) bool {
-	// Проверяем инструмент
-	if interaction.Tool == "hand" {
+	return g.HasTool(char, interaction.Tool)
+}
+
+// HasTool проверяет, есть ли у персонажа инструмент (hand - свободные руки)
+func (g *Game) HasTool(char *worldpkg.Character, tool string) bool {
+	if tool == config.HandTool {
 		return char.HandsFree
 	}
 
 	// Проверяем, есть ли нужный инструмент в экипировке
-	if equippedID, ok := char.Equipped[interaction.Tool]; ok {
+	if equippedID, ok := char.Equipped[tool]; ok {
 		itemConfig := g.GetItemConfig(equippedID)
 		return itemConfig != nil
 	}
@@ -261,84 +312,100 @@ func (g *Game) CanPerformInteraction(char *worldpkg.Character, interaction confi
 	return false
 }
 
-// AddToInventory добавляет предмет в инвентарь персонажа
-func (g *Game) AddToInventory(char *worldpkg.Character, itemID int, count int) {
+// AddToInventory добавляет предмет в инвентарь персонажа и возвращает количество добавленного.
+// Сначала дополняются неполные стопки того же предмета, затем занимаются свободные слоты;
+// то, что не поместилось, не добавляется.
+func (g *Game) AddToInventory(char *worldpkg.Character, itemID int, count int) int {
+	itemConfig := g.GetItemConfig(itemID)
+	if itemConfig == nil || count <= 0 {
+		return 0
+	}
 	if char.Inventory == nil {
 		char.Inventory = make(map[int]worldpkg.InventoryItem)
 	}
 
-	// Ищем слот с таким же предметом
-	for slotID, item := range char.Inventory {
-		if item.ItemID == itemID {
-			itemConfig := g.GetItemConfig(itemID)
-			if itemConfig != nil && item.Count+count <= itemConfig.StackSize {
-				item.Count += count
-				char.Inventory[slotID] = item
-				fmt.Printf("Добавлено %d x %s в инвентарь %s\n", count, itemConfig.Name, char.Name)
-				return
-			}
+	stackSize := g.stackSize(itemID)
+	remaining := count
+
+	// Дополняем стопки с таким же предметом
+	for _, slotID := range sortedSlots(char.Inventory) {
+		item := char.Inventory[slotID]
+		if remaining == 0 || item.ItemID != itemID || item.Count >= stackSize {
+			continue
 		}
+		added := min(remaining, stackSize-item.Count)
+		item.Count += added
+		char.Inventory[slotID] = item
+		remaining -= added
 	}
 
-	// Ищем свободный слот
-	for slotID := 0; slotID < 20; slotID++ {
-		if _, exists := char.Inventory[slotID]; !exists {
-			itemConfig := g.GetItemConfig(itemID)
-			if itemConfig != nil {
-				char.Inventory[slotID] = worldpkg.InventoryItem{
-					ItemID: itemID,
-					Count:  count,
-				}
-				fmt.Printf("Добавлено %d x %s в слот %d инвентаря %s\n", count, itemConfig.Name, slotID, char.Name)
-				return
-			}
+	// Занимаем свободные слоты
+	for slotID := 0; slotID < g.InventorySize && remaining > 0; slotID++ {
+		if _, exists := char.Inventory[slotID]; exists {
+			continue
 		}
+		added := min(remaining, stackSize)
+		char.Inventory[slotID] = worldpkg.InventoryItem{
+			ItemID: itemID,
+			Count:  added,
+		}
+		remaining -= added
 	}
 
-	fmt.Printf("Инвентарь %s полон!\n", char.Name)
+	added := count - remaining
+	if added > 0 {
+		fmt.Printf("Добавлено %d x %s в инвентарь %s\n", added, itemConfig.Name, char.Name)
+		g.inventoryChanged(char)
+	}
+	if remaining > 0 {
+		fmt.Printf("Инвентарь %s полон!\n", char.Name)
+	}
+	return added
 }
 
 // PerformInteraction выполняет взаимодействие с объектом
-func (g *Game) PerformInteraction(char *worldpkg.Character, objectID int, interaction config.Interaction) bool {
-	// Находим объект
-	obj := g.GetObjectAtPosition(char.Location, int(char.X+0.5))
-	if obj == nil || obj.ID != objectID {
-		// Проверяем соседние клетки
-		pos := int(char.X + 0.5)
-		neighborPositions := []int{pos - 1, pos, pos + 1}
-		for _, neighborPos := range neighborPositions {
-			if neighborPos >= 0 && neighborPos < len(g.State.LocationStates[char.Location].Road) {
-				if tempObj := g.GetObjectAtPosition(char.Location, neighborPos); tempObj != nil && tempObj.ID == objectID {
-					obj = tempObj
-					break
-				}
-			}
-		}
+func (g *Game) PerformInteraction(char *worldpkg.Character, objectID int, interaction config.Interaction) *InteractionOutcome {
+	outcome := &InteractionOutcome{
+		ObjectID:    objectID,
+		Interaction: interaction.Type,
 	}
 
-	if obj == nil {
-		fmt.Printf("Объект с ID %d не найден\n", objectID)
-		return false
+	// Находим объект
+	obj, failure := g.findObjectInReach(char, objectID)
+	if failure != FailureNone {
+		return outcome.fail(failure, g.describeFailure(failure, objectID, interaction))
 	}
 
 	// Проверяем возможность выполнения
 	if !g.CanPerformInteraction(char, interaction) {
-		fmt.Printf("%s не может выполнить это действие. Нужен инструмент: %s\n", char.Name, interaction.Tool)
-		return false
+		return outcome.fail(FailureToolRequired, g.describeFailure(FailureToolRequired, objectID, interaction))
 	}
 
 	// Проверяем прочность объекта
 	objConfig := g.GetObjectConfig(obj.TypeID)
 	if objConfig == nil {
-		return false
+		return outcome.fail(FailureInvalidInteraction, "Конфигурация объекта не найдена")
 	}
 
 	// Выполняем взаимодействие
 	fmt.Printf("%s выполняет действие '%s' с %s...\n", char.Name, interaction.Type, objConfig.Name)
 
 	// Добавляем предметы в инвентарь
-	for _, result := range interaction.Results {
-		g.AddToInventory(char, result.ItemID, result.Count)
+	for _, item := range g.RollResults(char, obj, interaction.Results) {
+		if added := g.AddToInventory(char, item.ItemID, item.Count); added > 0 {
+			outcome.Items = append(outcome.Items, worldpkg.InventoryItem{ItemID: item.ItemID, Count: added})
+		}
+	}
+
+	// Забираем содержимое хранилища; пока в нем что-то осталось, объект не расходуется
+	if interaction.TakeStorage {
+		outcome.Items = append(outcome.Items, g.takeStorage(char, obj)...)
+		if len(obj.Storage) > 0 {
+			fmt.Printf("%s: не все поместилось в инвентарь\n", objConfig.Name)
+			outcome.Success = true
+			outcome.Object = obj
+			return outcome
+		}
 	}
 
 	// Определяем сколько прочности отнимать
@@ -352,40 +419,61 @@ func (g *Game) PerformInteraction(char *worldpkg.Character, objectID int, intera
 
 	// Проверяем, нужно ли превращать объект в другой тип
 	if interaction.TransformTo > 0 && obj.Durability <= 0 {
-		// Превращаем объект
-		oldTypeID := obj.TypeID
-		obj.TypeID = interaction.TransformTo
-		obj.Durability = objConfig.MaxDurability // Сбрасываем прочность для нового объекта
-
-		// Обновляем конфиг
-		newObjConfig := g.GetObjectConfig(interaction.TransformTo)
-		if newObjConfig != nil {
-			fmt.Printf("%s превратился в %s!\n", objConfig.Name, newObjConfig.Name)
-		}
-
-		// Обновляем слой отображения
-		g.UpdateObjectLayer(obj.LocationID, obj.X, oldTypeID, interaction.TransformTo)
+		// Превращаем объект (новый тип начинает расти заново)
+		g.transformObject(obj, interaction.TransformTo)
+		outcome.TransformedTo = interaction.TransformTo
 	} else if interaction.DestroyOnComplete && obj.Durability <= 0 {
 		// Удаляем объект
 		g.RemoveObject(obj.ID)
 		fmt.Printf("%s уничтожен!\n", objConfig.Name)
+		outcome.Destroyed = true
 	} else if obj.Durability <= 0 {
 		// Если объект должен быть уничтожен, но не указано явно
 		g.RemoveObject(obj.ID)
 		fmt.Printf("%s уничтожен!\n", objConfig.Name)
+		outcome.Destroyed = true
 	} else {
 		// Объект еще жив, но прочность уменьшилась
 		fmt.Printf("%s: прочность %d/%d\n", objConfig.Name, obj.Durability, objConfig.MaxDurability)
+	}
+
+	outcome.Success = true
+	outcome.Object = obj
+	return outcome
+}
 
-		// Если это куст малины и прочность <= 0, превращаем в пустой куст
-		if obj.TypeID == 5 && obj.Durability <= 0 { // 5 - raspberry_bush
-			obj.TypeID = 9 // 9 - raspberry_bush_empty
-			obj.Durability = g.GetObjectConfig(9).MaxDurability
-			fmt.Printf("Куст малины опустел. Ягоды нужно ждать %d секунд.\n", g.GetObjectConfig(9).GrowthTime)
+// findObjectInReach находит объект на позиции персонажа или на соседних клетках
+func (g *Game) findObjectInReach(char *worldpkg.Character, objectID int) (*worldpkg.WorldObject, InteractionFailure) {
+	pos := int(char.X + 0.5)
+	for _, neighborPos := range []int{pos, pos - 1, pos + 1} {
+		if neighborPos >= 0 && neighborPos < len(g.State.LocationStates[char.Location].Road) {
+			if obj := g.GetObjectAtPosition(char.Location, neighborPos); obj != nil && obj.ID == objectID {
+				return obj, FailureNone
+			}
 		}
 	}
 
-	return true
+	// Объект существует, но персонаж до него не дотягивается
+	if obj, ok := g.GameWorld.Objects[objectID]; ok && obj != nil {
+		return nil, FailureOutOfReach
+	}
+
+	return nil, FailureObjectNotFound
+}
+
+// describeFailure возвращает сообщение о неудаче взаимодействия
+func (g *Game) describeFailure(failure InteractionFailure, objectID int, interaction config.Interaction) string {
+	switch failure {
+	case FailureObjectNotFound:
+		return fmt.Sprintf("Объект с ID %d не найден", objectID)
+	case FailureOutOfReach:
+		return fmt.Sprintf("Объект с ID %d слишком далеко", objectID)
+	case FailureToolRequired:
+		return fmt.Sprintf("Нужен инструмент: %s", interaction.Tool)
+	case FailureInvalidInteraction:
+		return "Действие не найдено"
+	}
+	return ""
 }
 
 // UpdateObjectLayer обновляет слой отображения объекта
@@ -395,6 +483,18 @@ func (g *Game) UpdateObjectLayer(locationID int, pos int, oldTypeID int, newType
 		return
 	}
 
+	if pos < 0 || pos >= len(locState.Foreground) || pos >= len(locState.Background) {
+		return
+	}
+
+	// Убираем старый тип из слоев (существо на клетке не трогаем)
+	if locState.Foreground[pos] == oldTypeID {
+		locState.Foreground[pos] = 0
+	}
+	if locState.Background[pos] == oldTypeID {
+		locState.Background[pos] = 0
+	}
+
 	// Обновляем слой в зависимости от типа объекта
 	objConfig := g.GetObjectConfig(newTypeID)
 	if objConfig == nil {
@@ -402,9 +502,11 @@ func (g *Game) UpdateObjectLayer(locationID int, pos int, oldTypeID int, newType
 	}
 
 	// Определяем, в каком слое должен быть объект
-	if objConfig.Foreground && pos >= 0 && pos < len(locState.Foreground) {
-		locState.Foreground[pos] = newTypeID
-	} else if objConfig.Background && pos >= 0 && pos < len(locState.Background) {
+	if objConfig.Foreground {
+		if locState.Foreground[pos] >= 0 {
+			locState.Foreground[pos] = newTypeID
+		}
+	} else if objConfig.Background {
 		locState.Background[pos] = newTypeID
 	}
 }
@@ -476,7 +578,7 @@ func (g *Game) UpdateCharacter(char *worldpkg.Character, elapsed float64) bool {
 	oldPos := int(char.X + 0.5)
 
 	if char.Direction != 0 {
-		char.X += float64(char.Direction) * char.Speed * elapsed
+		char.X += float64(char.Direction) * char.Speed * movementFactor(char) * elapsed
 
 		// Проверка границ локации
 		if char.Direction == 1 && char.X >= float64(len(roadLayer)-1) {
@@ -569,7 +671,7 @@ func (g *Game) TryTransition(char *worldpkg.Character, side string) {
 		g.State.CharsByLocation[char.Location] = append(g.State.CharsByLocation[char.Location], char)
 
 		fmt.Printf("%s перешел в локацию %d\n", char.Name, char.Location)
-		g.UpdateChan <- true
+		g.notifyUpdate()
 	} else {
 		char.Direction = 0
 		fmt.Printf("%s достиг края, но перехода нет\n", char.Name)
@@ -609,7 +711,7 @@ func (g *Game) UpdateCreature(creature *worldpkg.Creature, elapsed float64) {
 	creature.Hunger = min(100, creature.Hunger+int(elapsed*2))
 	creature.Thirst = min(100, creature.Thirst+int(elapsed*2))
 
-	creature.LastUpdate = time.Now()
+	creature.LastUpdate = g.Clock.Now()
 
 	// Если у существа нет поведения, устанавливаем поведение по умолчанию
 	if creature.CurrentBehavior == nil {
@@ -617,8 +719,20 @@ func (g *Game) UpdateCreature(creature *worldpkg.Creature, elapsed float64) {
 		return
 	}
 
-	// Проверяем, не завершилось ли текущее поведение
-	if time.Since(creature.CurrentBehavior.StartTime).Seconds() >= creature.CurrentBehavior.Duration {
+	// Перезарядка удара идет независимо от поведения
+	if creature.CurrentBehavior.Cooldown > 0 {
+		creature.CurrentBehavior.Cooldown = max(0, creature.CurrentBehavior.Cooldown-elapsed)
+	}
+
+	// Замечаем персонажей поблизости
+	if cfg := g.GetCreatureConfig(creature.TypeID); cfg != nil && g.reactToCharacters(creature, cfg) {
+		return
+	}
+
+	// Проверяем, не завершилось ли текущее поведение (длительность отсчитывается в тиках).
+	// Атака длится, пока существо не потеряет цель.
+	creature.CurrentBehavior.Ticks++
+	if creature.CurrentBehavior.Type != "attack" && creature.CurrentBehavior.Ticks >= secondsToTicks(creature.CurrentBehavior.Duration) {
 		// Поведение завершено, выбираем следующее
 		g.ChooseNextBehavior(creature)
 		return
@@ -633,11 +747,9 @@ func (g *Game) UpdateCreature(creature *worldpkg.Creature, elapsed float64) {
 	case "walk":
 		g.ExecuteWalkBehavior(creature, elapsed)
 	case "attack":
-		// Заглушка для атаки
-		g.ExecuteRestBehavior(creature, elapsed)
+		g.ExecuteAttackBehavior(creature, elapsed)
 	case "flee":
-		// Заглушка для бегства
-		g.ExecuteWanderBehavior(creature, elapsed)
+		g.ExecuteFleeBehavior(creature, elapsed)
 	}
 }
 
@@ -732,7 +844,6 @@ func (g *Game) ChooseNextBehavior(creature *worldpkg.Creature) {
 		creature.CurrentBehavior = &worldpkg.CreatureBehavior{
 			Type:             "rest",
 			TargetPos:        -1,
-			StartTime:        time.Now(),
 			Duration:         float64(g.RandomInt(10, 30)), // Дольше отдыхает
 			AteAtCurrentStop: false,
 		}
@@ -745,12 +856,15 @@ func (g *Game) ChooseNextBehavior(creature *worldpkg.Creature) {
 		availableBehaviors = []string{creatureConfig.DefaultBehavior}
 	}
 
-	// Убираем "eat" из списка, так как животные не ищут еду специально
+	// Убираем "eat" из списка, так как животные не ищут еду специально,
+	// а атака и бегство - реакция на персонажей, а не случайный выбор
 	filteredBehaviors := []string{}
 	for _, behavior := range availableBehaviors {
-		if behavior != "eat" { // Убираем целенаправленное поедание
-			filteredBehaviors = append(filteredBehaviors, behavior)
+		switch behavior {
+		case "eat", "attack", "flee":
+			continue
 		}
+		filteredBehaviors = append(filteredBehaviors, behavior)
 	}
 
 	if len(filteredBehaviors) == 0 {
@@ -762,7 +876,6 @@ func (g *Game) ChooseNextBehavior(creature *worldpkg.Creature) {
 	creature.CurrentBehavior = &worldpkg.CreatureBehavior{
 		Type:             randomBehavior,
 		TargetPos:        -1,
-		StartTime:        time.Now(),
 		Duration:         g.GetBehaviorDuration(randomBehavior),
 		AteAtCurrentStop: false,
 	}
@@ -874,11 +987,9 @@ func (g *Game) MoveCreatureToTarget(creature *worldpkg.Creature, elapsed float64
 	// ОБНОВЛЯЕМ позицию в слое (для отображения)
 	locState := g.State.LocationStates[creature.Location]
 	if locState != nil {
-		// Очищаем старую позицию (если это был последний на клетке)
-		if currentPos >= 0 && currentPos < len(locState.Foreground) {
-			// Проверяем, не осталось ли еще кого-то на клетке
-			// (пока просто очищаем - можно сделать счетчик)
-			locState.Foreground[currentPos] = 0
+		// Очищаем старую позицию, возвращая на нее объект, если он там есть
+		if currentPos >= 0 && currentPos < len(locState.Foreground) && locState.Foreground[currentPos] == -creature.ID {
+			locState.Foreground[currentPos] = g.foregroundObjectAt(creature.Location, currentPos)
 		}
 		// Занимаем новую позицию
 		if newPos >= 0 && newPos < len(locState.Foreground) {
@@ -903,7 +1014,6 @@ func (g *Game) SetDefaultBehavior(creature *worldpkg.Creature) {
 	creature.CurrentBehavior = &worldpkg.CreatureBehavior{
 		Type:             creatureConfig.DefaultBehavior,
 		TargetPos:        -1,
-		StartTime:        time.Now(),
 		Duration:         g.GetBehaviorDuration(creatureConfig.DefaultBehavior),
 		AteAtCurrentStop: false,
 	}
@@ -978,7 +1088,8 @@ func (g *Game) PrintInventory(char *worldpkg.Character) {
 	}
 
 	totalWeight := 0.0
-	for slotID, item := range char.Inventory {
+	for _, slotID := range sortedSlots(char.Inventory) {
+		item := char.Inventory[slotID]
 		itemConfig := g.GetItemConfig(item.ItemID)
 		if itemConfig != nil {
 			slotWeight := float64(item.Count) * itemConfig.Weight
@@ -988,7 +1099,7 @@ func (g *Game) PrintInventory(char *worldpkg.Character) {
 		}
 	}
 
-	fmt.Printf("Общий вес: %.2f кг\n", totalWeight)
+	fmt.Printf("Занято слотов: %d/%d, общий вес: %.2f кг\n", len(char.Inventory), g.InventorySize, totalWeight)
 
 	// Экипировка
 	if len(char.Equipped) > 0 {
@@ -1015,7 +1126,6 @@ func (g *Game) PrintAvailableInteractions(char *worldpkg.Character) {
 
 	fmt.Printf("\n=== ДОСТУПНЫЕ ВЗАИМОДЕЙСТВИЯ ДЛЯ %s ===\n", char.Name)
 
-	interactionIndex := 0
 	pos := int(char.X + 0.5)
 
 	// Проверяем текущую позицию
@@ -1024,10 +1134,12 @@ func (g *Game) PrintAvailableInteractions(char *worldpkg.Character) {
 		if objConfig != nil {
 			fmt.Printf("\nОбъект на позиции %d: %s (ID: %d) прочность: %d/%d\n",
 				pos, objConfig.Name, obj.ID, obj.Durability, objConfig.MaxDurability)
+			interactionIndex := 0
 			for _, interaction := range objConfig.Interactions {
 				if g.CanPerformInteraction(char, interaction) {
 					fmt.Printf("  [%d] %s (инструмент: %s, время: %dс)\n",
 						interactionIndex, interaction.Type, interaction.Tool, interaction.Time)
+					interactionIndex++
 
 					// Показываем эффекты
 					if interaction.ReduceDurability > 0 {
@@ -1044,13 +1156,7 @@ func (g *Game) PrintAvailableInteractions(char *worldpkg.Character) {
 					}
 
 					// Показываем награды
-					for _, result := range interaction.Results {
-						itemConfig := g.GetItemConfig(result.ItemID)
-						if itemConfig != nil {
-							fmt.Printf("      -> %d x %s\n", result.Count, itemConfig.Name)
-						}
-					}
-					interactionIndex++
+					g.printResults(interaction.Results, "      ")
 				}
 			}
 		}
@@ -1065,10 +1171,12 @@ func (g *Game) PrintAvailableInteractions(char *worldpkg.Character) {
 				if objConfig != nil {
 					fmt.Printf("\nОбъект на позиции %d: %s (ID: %d) прочность: %d/%d\n",
 						neighborPos, objConfig.Name, obj.ID, obj.Durability, objConfig.MaxDurability)
+					interactionIndex := 0
 					for _, interaction := range objConfig.Interactions {
 						if g.CanPerformInteraction(char, interaction) {
 							fmt.Printf("  [%d] %s (инструмент: %s, время: %dс)\n",
 								interactionIndex, interaction.Type, interaction.Tool, interaction.Time)
+							interactionIndex++
 
 							// Показываем эффекты
 							if interaction.ReduceDurability > 0 {
@@ -1085,13 +1193,7 @@ func (g *Game) PrintAvailableInteractions(char *worldpkg.Character) {
 							}
 
 							// Показываем награды
-							for _, result := range interaction.Results {
-								itemConfig := g.GetItemConfig(result.ItemID)
-								if itemConfig != nil {
-									fmt.Printf("      -> %d x %s\n", result.Count, itemConfig.Name)
-								}
-							}
-							interactionIndex++
+							g.printResults(interaction.Results, "      ")
 						}
 					}
 				}
@@ -1102,54 +1204,94 @@ func (g *Game) PrintAvailableInteractions(char *worldpkg.Character) {
 	fmt.Println("\nДля выполнения действия введите: act <ID объекта> <номер действия>")
 }
 
-// PerformInteractionByIndex выполняет взаимодействие по индексу
-func (g *Game) PerformInteractionByIndex(char *worldpkg.Character, objectID int, interactionIndex int) {
-	// Находим объект
-	obj := g.GetObjectAtPosition(char.Location, int(char.X+0.5))
-	if obj == nil || obj.ID != objectID {
-		// Проверяем соседние клетки
-		pos := int(char.X + 0.5)
-		neighborPositions := []int{pos - 1, pos, pos + 1}
-		for _, neighborPos := range neighborPositions {
-			if neighborPos >= 0 && neighborPos < len(g.State.LocationStates[char.Location].Road) {
-				if tempObj := g.GetObjectAtPosition(char.Location, neighborPos); tempObj != nil && tempObj.ID == objectID {
-					obj = tempObj
-					break
-				}
+// PerformInteractionByIndex выполняет взаимодействие по индексу в конфигурации объекта.
+// Используется для завершения длительного действия, команды игроков идут через StartInteraction.
+func (g *Game) PerformInteractionByIndex(char *worldpkg.Character, objectID int, interactionIndex int) *InteractionOutcome {
+	interaction, failure := g.resolveInteraction(char, objectID, interactionIndex)
+	if failure != nil {
+		fmt.Printf("%s: %s\n", char.Name, failure.Message)
+		return failure
+	}
+
+	return g.PerformInteraction(char, objectID, interaction)
+}
+
+// resolveAvailableInteraction находит взаимодействие по номеру среди доступных персонажу
+// взаимодействий объекта (так их нумерует PrintAvailableInteractions) и возвращает его
+// вместе с индексом в конфиге объекта. При неудаче возвращает результат с причиной.
+func (g *Game) resolveAvailableInteraction(char *worldpkg.Character, objectID int, number int) (config.Interaction, int, *InteractionOutcome) {
+	outcome := &InteractionOutcome{ObjectID: objectID}
+
+	objConfig, failure := g.objectConfigInReach(char, objectID, outcome)
+	if failure != nil {
+		return config.Interaction{}, 0, failure
+	}
+
+	index := 0
+	unavailable := -1
+	for configIndex, interaction := range objConfig.Interactions {
+		if !g.CanPerformInteraction(char, interaction) {
+			if unavailable < 0 {
+				unavailable = configIndex
 			}
+			continue
+		}
+		if index == number {
+			outcome.Interaction = interaction.Type
+			return interaction, configIndex, nil
 		}
+		index++
 	}
 
-	if obj == nil {
-		fmt.Printf("Объект с ID %d не найден\n", objectID)
-		return
+	// Номер указывает за доступные действия - значит, для остальных не хватает инструмента
+	if unavailable >= 0 && number >= 0 && number < len(objConfig.Interactions) {
+		interaction := objConfig.Interactions[unavailable]
+		outcome.Interaction = interaction.Type
+		return config.Interaction{}, 0, outcome.fail(FailureToolRequired, g.describeFailure(FailureToolRequired, objectID, interaction))
 	}
+	return config.Interaction{}, 0, outcome.fail(FailureInvalidInteraction,
+		fmt.Sprintf("Действие с номером %d не найдено или недоступно", number))
+}
 
-	objConfig := g.GetObjectConfig(obj.TypeID)
-	if objConfig == nil {
-		fmt.Printf("Конфигурация объекта не найдена\n")
-		return
+// resolveInteraction находит взаимодействие по индексу в конфиге объекта и проверяет, что персонаж
+// может его выполнить (повторная проверка при завершении длительного действия).
+// При неудаче возвращает результат с причиной.
+func (g *Game) resolveInteraction(char *worldpkg.Character, objectID int, interactionIndex int) (config.Interaction, *InteractionOutcome) {
+	outcome := &InteractionOutcome{ObjectID: objectID}
+
+	objConfig, failure := g.objectConfigInReach(char, objectID, outcome)
+	if failure != nil {
+		return config.Interaction{}, failure
 	}
 
 	// Находим взаимодействие по индексу
-	index := 0
-	for _, interaction := range objConfig.Interactions {
-		if g.CanPerformInteraction(char, interaction) {
-			if index == interactionIndex {
-				g.PerformInteraction(char, objectID, interaction)
-				return
-			}
-			index++
-		}
+	if interactionIndex < 0 || interactionIndex >= len(objConfig.Interactions) {
+		return config.Interaction{}, outcome.fail(FailureInvalidInteraction,
+			fmt.Sprintf("Действие с индексом %d не найдено", interactionIndex))
+	}
+
+	interaction := objConfig.Interactions[interactionIndex]
+	outcome.Interaction = interaction.Type
+	if !g.CanPerformInteraction(char, interaction) {
+		return config.Interaction{}, outcome.fail(FailureToolRequired, g.describeFailure(FailureToolRequired, objectID, interaction))
 	}
 
-	fmt.Printf("Действие с индексом %d не найдено или недоступно\n", interactionIndex)
+	return interaction, nil
 }
 
-// UpdateWorldObjects обновляет состояние объектов мира
-func (g *Game) UpdateWorldObjects(elapsed float64) {
-	// В будущем здесь будет логика роста объектов
-	// Например, проверка growth_time и обновление growth_stage
+// objectConfigInReach находит конфигурацию объекта в пределах досягаемости персонажа.
+// При неудаче заполняет outcome причиной и возвращает его.
+func (g *Game) objectConfigInReach(char *worldpkg.Character, objectID int, outcome *InteractionOutcome) (*config.ObjectTypeConfig, *InteractionOutcome) {
+	obj, failure := g.findObjectInReach(char, objectID)
+	if failure != FailureNone {
+		return nil, outcome.fail(failure, g.describeFailure(failure, objectID, config.Interaction{}))
+	}
+
+	objConfig := g.GetObjectConfig(obj.TypeID)
+	if objConfig == nil {
+		return nil, outcome.fail(FailureInvalidInteraction, "Конфигурация объекта не найдена")
+	}
+	return objConfig, nil
 }
 
 func (g *Game) PrintState() {
@@ -1272,8 +1414,9 @@ func (g *Game) PrintState() {
 				if char.Controlled == g.GameWorld.PlayerID {
 					controlStatus = "ИГРОК"
 				}
-				fmt.Printf("  %s (ID: %d) поз: %.1f, напр: %d, верт: %d, скорость: %.1f [%s]\n",
-					char.Name, char.ID, char.X, char.Direction, char.Vertical, char.Speed, controlStatus)
+				fmt.Printf("  %s (ID: %d) поз: %.1f, напр: %d, верт: %d, скорость: %.1f, здоровье: %d/%d, голод: %.0f, жажда: %.0f, выносливость: %.0f [%s]\n",
+					char.Name, char.ID, char.X, char.Direction, char.Vertical, char.Speed, char.Health, char.MaxHealth,
+					char.Hunger, char.Thirst, char.Stamina, controlStatus)
 			}
 		}
 
@@ -1336,7 +1479,7 @@ func (g *Game) PrintState() {
 			len(g.Registries.CreatureTypeByID))
 	}
 
-	fmt.Println("\nКоманды: a/d - влево/вправо, w/s - вверх/вниз, stop - остановка, i - инвентарь, act - взаимодействия, x - состояние, save - сохранить, exit - выход")
+	fmt.Println("\nКоманды: a/d - влево/вправо, w/s - вверх/вниз, stop - остановка, i - инвентарь, move/split/drop - работа с инвентарем, equip/unequip - инструмент в руки, act - взаимодействия, attack <id> - ударить существо, eat <слот> - съесть, drink - попить, x - состояние, save - сохранить, exit - выход")
 }
 
 func (g *Game) HandleInput(input string) {
@@ -1371,27 +1514,31 @@ func (g *Game) HandleInput(input string) {
 		fmt.Printf("%s остановился\n", playerChar.Name)
 	case "i":
 		g.PrintInventory(playerChar)
+	case "drink":
+		if err := g.Drink(playerChar); err != nil {
+			fmt.Printf("Не удалось попить: %v\n", err)
+		}
 	case "act":
 		g.PrintAvailableInteractions(playerChar)
+		g.PrintCreaturesInReach(playerChar)
 	case "x":
 		g.PrintState()
 	case "save":
 		// Сохраняем только игровое состояние (без конфигов)
-		saveWorld := &worldpkg.World{
-			PlayerID:   g.GameWorld.PlayerID,
-			Characters: g.GameWorld.Characters,
-			Locations:  g.GameWorld.Locations,
-			Objects:    g.GameWorld.Objects,
-			Creatures:  g.GameWorld.Creatures,
-		}
-		if err := worldpkg.SaveWorld(saveWorld, "data/save/world.json"); err != nil {
+		data, err := g.MarshalSave()
+		if err == nil {
+			var saveFile string
+			saveFile, err = g.writeSave(data)
+			if err == nil {
+				fmt.Printf("Мир сохранен в %s\n", saveFile)
+			}
+		}
+		if err != nil {
 			fmt.Printf("Ошибка сохранения: %v\n", err)
-		} else {
-			fmt.Println("Мир сохранен в data/save/world.json")
 		}
 	case "exit":
+		// Цикл завершится после текущей итерации
 		g.State.Running = false
-		g.ExitChan <- true
 	default:
 		// Пробуем выполнить действие формата "act <id> <index>"
 		if strings.HasPrefix(input, "act ") {
@@ -1400,18 +1547,52 @@ func (g *Game) HandleInput(input string) {
 				var objectID, interactionIndex int
 				if _, err := fmt.Sscanf(parts[1], "%d", &objectID); err == nil {
 					if _, err := fmt.Sscanf(parts[2], "%d", &interactionIndex); err == nil {
-						g.PerformInteractionByIndex(playerChar, objectID, interactionIndex)
+						g.StartInteraction(playerChar, objectID, interactionIndex)
 						return
 					}
 				}
 			}
 		}
-		fmt.Println("Неизвестная команда. Доступные: a, d, w, s, stop, i, act, x, save, exit, act <id> <index>")
+		// Атака формата "attack <creature_id>"
+		if strings.HasPrefix(input, "attack ") {
+			var creatureID int
+			if _, err := fmt.Sscanf(strings.TrimPrefix(input, "attack "), "%d", &creatureID); err == nil {
+				if outcome := g.AttackCreature(playerChar, creatureID); !outcome.Success {
+					fmt.Printf("%s: %s\n", playerChar.Name, outcome.Message)
+				}
+				return
+			}
+		}
+		// Операции с инвентарем: "move <from> <to>", "split <from> <to> <count>", "drop <slot> [count]",
+		// "equip <slot>", "unequip [tool_kind]"
+		if g.handleInventoryInput(playerChar, input) {
+			return
+		}
+		// Еда формата "eat <slot>"
+		if strings.HasPrefix(input, "eat ") {
+			var slot int
+			if _, err := fmt.Sscanf(strings.TrimPrefix(input, "eat "), "%d", &slot); err == nil {
+				if err := g.Eat(playerChar, slot); err != nil {
+					fmt.Printf("Не удалось поесть: %v\n", err)
+				}
+				return
+			}
+		}
+		fmt.Println("Неизвестная команда. Доступные: a, d, w, s, stop, i, move <from> <to>, split <from> <to> <count>, drop <slot> [count], equip <slot>, unequip [kind], act, attack <id>, eat <slot>, drink, x, save, exit, act <id> <index>")
 	}
 }
 
+// RunGameLoop выполняет команды и продвигает симуляцию фиксированными шагами TickInterval.
+// Время берется из g.Clock: если цикл отстал, он догоняет до maxCatchUpTicks тиков за раз.
 func (g *Game) RunGameLoop() {
-	lastUpdate := time.Now()
+	g.started.Store(true)
+	defer close(g.stopped)
+
+	ticker := g.Clock.NewTicker(TickInterval)
+	defer ticker.Stop()
+
+	lastUpdate := g.Clock.Now()
+	var lag time.Duration
 
 	for g.State.Running {
 		select {
@@ -1420,39 +1601,100 @@ func (g *Game) RunGameLoop() {
 			return
 		case input := <-g.InputChan:
 			g.HandleInput(input)
-		case <-time.After(16 * time.Millisecond):
-			currentTime := time.Now()
-			elapsed := currentTime.Sub(lastUpdate).Seconds()
-			lastUpdate = currentTime
-
+			g.flushInventoryUpdates()
+		case cmd := <-g.CommandChan:
+			cmd(g)
+			g.flushInventoryUpdates()
+		case <-ticker.C():
+			started := time.Now()
+
+			now := g.Clock.Now()
+			lag += now.Sub(lastUpdate)
+			lastUpdate = now
+
+			steps := 0
 			updated := false
-
-			// Обновляем персонажей
-			for _, char := range g.GameWorld.Characters {
-				if g.UpdateCharacter(char, elapsed) {
+			for lag >= TickInterval {
+				if steps == maxCatchUpTicks {
+					// Цикл сильно отстал - не пытаемся догнать все сразу
+					lag = 0
+					break
+				}
+				if g.Step() {
 					updated = true
 				}
+				lag -= TickInterval
+				steps++
 			}
-
-			// Обновляем существ
-			for _, creature := range g.GameWorld.Creatures {
-				g.UpdateCreature(creature, elapsed)
-				updated = true // Всегда обновляем, так как существа могут двигаться
+			if steps == 0 {
+				continue
 			}
+			g.flushInventoryUpdates()
 
-			// Обновляем объекты мира (рост, восстановление)
-			g.UpdateWorldObjects(elapsed)
+			// Публикуем снимок для сетевых читателей
+			g.publishSnapshot()
 
 			if updated {
-				select {
-				case g.UpdateChan <- true:
-				default:
-				}
+				g.notifyUpdate()
 			}
+
+			g.tickDuration.Observe(time.Since(started).Seconds())
 		}
 	}
 }
 
+// Step продвигает симуляцию ровно на один тик (TickSeconds секунд).
+// Вызывается только из игрового цикла. Результат зависит только от состояния мира,
+// команд и seed генератора, поэтому одинаковые входные данные дают одинаковый мир.
+// Возвращает true, если мир изменился.
+func (g *Game) Step() bool {
+	g.GameWorld.Tick++
+
+	updated := false
+
+	// Обновляем персонажей
+	for _, char := range g.GameWorld.Characters {
+		g.UpdateCharacterAction(char, TickSeconds)
+		if g.UpdateCharacter(char, TickSeconds) {
+			updated = true
+		}
+		if g.UpdateVitals(char, TickSeconds) {
+			updated = true
+		}
+	}
+
+	// Обновляем существ
+	for _, creature := range g.GameWorld.Creatures {
+		g.UpdateCreature(creature, TickSeconds)
+		updated = true // Всегда обновляем, так как существа могут двигаться
+	}
+
+	// Обновляем объекты мира (рост, восстановление)
+	if g.UpdateWorldObjects(TickSeconds) {
+		updated = true
+	}
+
+	return updated
+}
+
+// Tick возвращает номер последнего выполненного тика симуляции
+func (g *Game) Tick() uint64 {
+	return g.GameWorld.Tick
+}
+
+// secondsToTicks переводит длительность в секундах в число тиков
+func secondsToTicks(seconds float64) int {
+	return int(math.Ceil(seconds * TickRate))
+}
+
+// notifyUpdate сигнализирует об изменении мира, не блокируя игровой цикл
+func (g *Game) notifyUpdate() {
+	select {
+	case g.UpdateChan <- true:
+	default:
+	}
+}
+
 // Helper function for layer printing
 func (g *Game) PrintLayer(name string, layer []int, getSymbol func(int) string) {
 	fmt.Printf("%s: [", name)
@@ -1528,7 +1770,7 @@ func (g *Game) RemoveCreature(creatureID int) {
 	if locState != nil {
 		pos := int(creature.X + 0.5)
 		if pos >= 0 && pos < len(locState.Foreground) && locState.Foreground[pos] == -creature.ID {
-			locState.Foreground[pos] = 0
+			locState.Foreground[pos] = g.foregroundObjectAt(creature.Location, pos)
 		}
 	}
 
@@ -0,0 +1,160 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// growthUpdateInterval - как часто (в секундах) пересчитывать рост объектов
+const growthUpdateInterval = 1.0
+
+// UpdateWorldObjects обновляет состояние объектов мира (рост, восстановление).
+// Рост пересчитывается не каждый тик, а раз в growthUpdateInterval секунд.
+// Возвращает true, если хотя бы один объект изменился.
+func (g *Game) UpdateWorldObjects(elapsed float64) bool {
+	g.growthElapsed += elapsed
+	if g.growthElapsed < growthUpdateInterval {
+		return false
+	}
+
+	changed := g.AdvanceGrowth(g.growthElapsed)
+	g.growthElapsed = 0
+	return changed
+}
+
+// AdvanceGrowth продвигает рост всех объектов на seconds секунд.
+// Возвращает true, если хотя бы один объект изменился.
+func (g *Game) AdvanceGrowth(seconds float64) bool {
+	if seconds <= 0 {
+		return false
+	}
+
+	// Обходим объекты в порядке ID, чтобы результат не зависел от порядка обхода map
+	ids := make([]int, 0, len(g.GameWorld.Objects))
+	for id := range g.GameWorld.Objects {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	changed := false
+	for _, id := range ids {
+		if g.growObject(g.GameWorld.Objects[id], seconds) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// growObject продвигает рост одного объекта.
+// Если объект вырастает, он превращается в следующий тип, а оставшееся время
+// идет в рост нового типа (важно при наверстывании долгого простоя сервера).
+func (g *Game) growObject(obj *worldpkg.WorldObject, seconds float64) bool {
+	changed := false
+
+	for seconds > 0 {
+		objConfig := g.GetObjectConfig(obj.TypeID)
+		if objConfig == nil || objConfig.GrowthTime <= 0 {
+			return changed
+		}
+
+		// Взрослый объект, которому некуда дальше расти
+		if obj.GrowthStage >= 100 && objConfig.GrowsInto == 0 {
+			return changed
+		}
+
+		growthTime := float64(objConfig.GrowthTime)
+		remaining := growthTime - obj.GrowthTime
+		if seconds < remaining {
+			obj.GrowthTime += seconds
+			if stage := int(obj.GrowthTime / growthTime * 100); stage != obj.GrowthStage {
+				obj.GrowthStage = stage
+				changed = true
+			}
+			return changed
+		}
+
+		// Объект вырос
+		seconds -= max(remaining, 0)
+		obj.GrowthTime = growthTime
+		obj.GrowthStage = 100
+		changed = true
+
+		if objConfig.GrowsInto == 0 {
+			return changed
+		}
+		g.transformObject(obj, objConfig.GrowsInto)
+	}
+
+	return changed
+}
+
+// transformObject превращает объект в другой тип и начинает его рост заново
+func (g *Game) transformObject(obj *worldpkg.WorldObject, newTypeID int) {
+	oldTypeID := obj.TypeID
+	oldConfig := g.GetObjectConfig(oldTypeID)
+	newConfig := g.GetObjectConfig(newTypeID)
+
+	obj.TypeID = newTypeID
+	if newConfig != nil {
+		obj.Durability = newConfig.MaxDurability // Сбрасываем прочность для нового объекта
+	}
+	g.resetGrowth(obj)
+
+	if oldConfig != nil && newConfig != nil {
+		fmt.Printf("%s превратился в %s!\n", oldConfig.Name, newConfig.Name)
+	}
+
+	// Обновляем слой отображения
+	g.UpdateObjectLayer(obj.LocationID, obj.X, oldTypeID, newTypeID)
+}
+
+// resetGrowth сбрасывает рост объекта: типы, которым есть во что вырасти, начинают с нуля,
+// остальные считаются взрослыми
+func (g *Game) resetGrowth(obj *worldpkg.WorldObject) {
+	obj.GrowthTime = 0
+	obj.GrowthStage = 100
+	if objConfig := g.GetObjectConfig(obj.TypeID); objConfig != nil && objConfig.GrowthTime > 0 && objConfig.GrowsInto > 0 {
+		obj.GrowthStage = 0
+	}
+}
+
+// initGrowth восстанавливает накопленное время роста для объектов,
+// у которых сохранена только стадия (старые сохранения и начальный мир)
+func (g *Game) initGrowth(obj *worldpkg.WorldObject) {
+	objConfig := g.GetObjectConfig(obj.TypeID)
+	if objConfig == nil || objConfig.GrowthTime <= 0 || obj.GrowthTime > 0 {
+		return
+	}
+	obj.GrowthStage = min(max(obj.GrowthStage, 0), 100)
+	obj.GrowthTime = float64(objConfig.GrowthTime) * float64(obj.GrowthStage) / 100
+}
+
+// catchUpGrowth продвигает рост на время, прошедшее с момента сохранения мира
+func (g *Game) catchUpGrowth(now time.Time) {
+	if g.GameWorld.SavedAt.IsZero() {
+		return
+	}
+
+	offline := now.Sub(g.GameWorld.SavedAt).Seconds()
+	if offline <= 0 {
+		return
+	}
+
+	fmt.Printf("Мир не обновлялся %.0f с, догоняем рост объектов\n", offline)
+	g.AdvanceGrowth(offline)
+}
+
+// foregroundObjectAt возвращает тип объекта переднего плана на позиции (0 - нет объекта)
+func (g *Game) foregroundObjectAt(locationID int, pos int) int {
+	for _, obj := range g.State.ObjectsByLocation[locationID] {
+		if obj.X != pos {
+			continue
+		}
+		if objConfig := g.GetObjectConfig(obj.TypeID); objConfig != nil && objConfig.Foreground {
+			return obj.TypeID
+		}
+	}
+	return 0
+}
@@ -0,0 +1,237 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultInventorySize - число слотов инвентаря по умолчанию
+const DefaultInventorySize = 20
+
+// Ошибки операций с инвентарем
+var (
+	ErrInvalidSlot  = errors.New("неверный номер слота")
+	ErrEmptySlot    = errors.New("слот инвентаря пуст")
+	ErrSlotOccupied = errors.New("слот занят")
+	ErrInvalidCount = errors.New("неверное количество")
+	ErrStackFull    = errors.New("стопка заполнена")
+	ErrCannotDrop   = errors.New("предметы некуда положить")
+)
+
+// stackSize возвращает размер стопки предмета (не меньше 1)
+func (g *Game) stackSize(itemID int) int {
+	if itemConfig := g.GetItemConfig(itemID); itemConfig != nil && itemConfig.StackSize > 0 {
+		return itemConfig.StackSize
+	}
+	return 1
+}
+
+// validSlot проверяет, что номер слота есть в инвентаре
+func (g *Game) validSlot(slot int) bool {
+	return slot >= 0 && slot < g.InventorySize
+}
+
+// takeFromSlot убирает count предметов из слота (слот должен содержать не меньше count)
+func (g *Game) takeFromSlot(char *worldpkg.Character, slot int, count int) {
+	item := char.Inventory[slot]
+	item.Count -= count
+	if item.Count <= 0 {
+		delete(char.Inventory, slot)
+	} else {
+		char.Inventory[slot] = item
+	}
+	g.inventoryChanged(char)
+}
+
+// MoveItem перекладывает предмет из слота from в слот to.
+// Одинаковые предметы объединяются в пределах размера стопки (остаток остается в from),
+// разные - меняются местами.
+func (g *Game) MoveItem(char *worldpkg.Character, from, to int) error {
+	if !g.validSlot(from) || !g.validSlot(to) || from == to {
+		return ErrInvalidSlot
+	}
+	item, ok := char.Inventory[from]
+	if !ok {
+		return ErrEmptySlot
+	}
+
+	target, occupied := char.Inventory[to]
+	switch {
+	case !occupied:
+		char.Inventory[to] = item
+		delete(char.Inventory, from)
+	case target.ItemID == item.ItemID:
+		moved := min(item.Count, g.stackSize(item.ItemID)-target.Count)
+		if moved <= 0 {
+			return ErrStackFull
+		}
+		target.Count += moved
+		char.Inventory[to] = target
+		g.takeFromSlot(char, from, moved)
+	default:
+		char.Inventory[to] = item
+		char.Inventory[from] = target
+	}
+
+	g.inventoryChanged(char)
+	return nil
+}
+
+// SplitStack отделяет count предметов из слота from в пустой слот to
+func (g *Game) SplitStack(char *worldpkg.Character, from, to, count int) error {
+	if !g.validSlot(from) || !g.validSlot(to) || from == to {
+		return ErrInvalidSlot
+	}
+	item, ok := char.Inventory[from]
+	if !ok {
+		return ErrEmptySlot
+	}
+	if _, occupied := char.Inventory[to]; occupied {
+		return ErrSlotOccupied
+	}
+	if count <= 0 || count >= item.Count {
+		return ErrInvalidCount
+	}
+
+	g.takeFromSlot(char, from, count)
+	char.Inventory[to] = worldpkg.InventoryItem{ItemID: item.ItemID, Count: count}
+	return nil
+}
+
+// DropItem выкладывает на землю count предметов из слота (count <= 0 - всю стопку).
+// Предметы попадают в хранилище на клетке персонажа (или в новое рядом с ним),
+// откуда их можно забрать взаимодействием.
+func (g *Game) DropItem(char *worldpkg.Character, slot, count int) (*worldpkg.WorldObject, error) {
+	if !g.validSlot(slot) {
+		return nil, ErrInvalidSlot
+	}
+	item, ok := char.Inventory[slot]
+	if !ok {
+		return nil, ErrEmptySlot
+	}
+	if count <= 0 {
+		count = item.Count
+	}
+	if count > item.Count {
+		return nil, ErrInvalidCount
+	}
+	if g.containerType() == nil {
+		return nil, ErrCannotDrop
+	}
+
+	dropped := worldpkg.InventoryItem{ItemID: item.ItemID, Count: count}
+	obj := g.containerAt(char.Location, int(char.X+0.5))
+	if obj != nil {
+		obj.Storage[dropped.ItemID] += dropped.Count
+	} else if obj = g.DropContainer(char.Location, char.X, []worldpkg.InventoryItem{dropped}); obj == nil {
+		return nil, ErrCannotDrop
+	}
+	g.takeFromSlot(char, slot, count)
+
+	itemName := fmt.Sprintf("предмет %d", item.ItemID)
+	if itemConfig := g.GetItemConfig(item.ItemID); itemConfig != nil {
+		itemName = itemConfig.Name
+	}
+	fmt.Printf("%s выложил %d x %s на клетку %d\n", char.Name, count, itemName, obj.X)
+	return obj, nil
+}
+
+// containerAt возвращает хранилище на клетке pos локации или nil
+func (g *Game) containerAt(locationID int, pos int) *worldpkg.WorldObject {
+	obj := g.GetObjectAtPosition(locationID, pos)
+	if obj == nil {
+		return nil
+	}
+	if objConfig := g.GetObjectConfig(obj.TypeID); objConfig == nil || !objConfig.Container {
+		return nil
+	}
+	if obj.Storage == nil {
+		obj.Storage = make(map[int]int)
+	}
+	return obj
+}
+
+// inventoryChanged отмечает, что инвентарь персонажа изменился.
+// Клиенты узнают об этом из события inventory_changed после выполнения команды или тика.
+func (g *Game) inventoryChanged(char *worldpkg.Character) {
+	g.inventoryDirty[char.ID] = true
+}
+
+// flushInventoryUpdates отправляет по одному событию inventory_changed на каждый измененный инвентарь
+func (g *Game) flushInventoryUpdates() {
+	if len(g.inventoryDirty) == 0 {
+		return
+	}
+
+	ids := make([]int, 0, len(g.inventoryDirty))
+	for id := range g.inventoryDirty {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	clear(g.inventoryDirty)
+
+	for _, id := range ids {
+		if char := g.GetCharacterByID(id); char != nil {
+			g.emit(GameEvent{
+				Type:        EventInventoryChanged,
+				CharacterID: char.ID,
+				LocationID:  char.Location,
+			})
+		}
+	}
+}
+
+// handleInventoryInput выполняет консольные команды инвентаря.
+// Возвращает false, если input не является такой командой.
+func (g *Game) handleInventoryInput(char *worldpkg.Character, input string) bool {
+	var err error
+	var from, to, count int
+
+	switch {
+	case strings.HasPrefix(input, "move "):
+		if _, scanErr := fmt.Sscanf(input, "move %d %d", &from, &to); scanErr != nil {
+			return false
+		}
+		err = g.MoveItem(char, from, to)
+	case strings.HasPrefix(input, "split "):
+		if _, scanErr := fmt.Sscanf(input, "split %d %d %d", &from, &to, &count); scanErr != nil {
+			return false
+		}
+		err = g.SplitStack(char, from, to, count)
+	case strings.HasPrefix(input, "equip "):
+		if _, scanErr := fmt.Sscanf(input, "equip %d", &from); scanErr != nil {
+			return false
+		}
+		err = g.Equip(char, from)
+	case input == "unequip" || strings.HasPrefix(input, "unequip "):
+		err = g.Unequip(char, strings.TrimSpace(strings.TrimPrefix(input, "unequip")))
+	case strings.HasPrefix(input, "drop "):
+		// Количество необязательно - без него выкладывается вся стопка
+		if n, _ := fmt.Sscanf(input, "drop %d %d", &from, &count); n == 0 {
+			return false
+		}
+		_, err = g.DropItem(char, from, count)
+	default:
+		return false
+	}
+
+	if err != nil {
+		fmt.Printf("Не удалось: %v\n", err)
+		return true
+	}
+	g.PrintInventory(char)
+	return true
+}
+
+// sortedSlots возвращает номера занятых слотов инвентаря по возрастанию
+func sortedSlots(inventory map[int]worldpkg.InventoryItem) []int {
+	slots := make([]int, 0, len(inventory))
+	for slot := range inventory {
+		slots = append(slots, slot)
+	}
+	slices.Sort(slots)
+	return slots
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"LOIL-server/internal/config"
+	worldpkg "LOIL-server/internal/world"
+	"fmt"
+	"math/rand"
+)
+
+// SetSeed задает начальное значение генератора случайных чисел игры.
+// С одинаковым seed броски добычи и поведение существ повторяются.
+func (g *Game) SetSeed(seed int64) {
+	g.seed = seed
+	g.rand = rand.New(rand.NewSource(seed))
+}
+
+// Seed возвращает начальное значение генератора, чтобы запуск можно было повторить
+func (g *Game) Seed() int64 {
+	return g.seed
+}
+
+// RollResults определяет предметы, которые выпадут при взаимодействии с объектом
+// (или с погибшего существа, тогда obj равен nil). Строки таблицы обрабатываются по порядку, все броски идут через генератор игры.
+func (g *Game) RollResults(char *worldpkg.Character, obj *worldpkg.WorldObject, results []config.InteractionResult) []worldpkg.InventoryItem {
+	var items []worldpkg.InventoryItem
+	for _, result := range results {
+		items = g.rollResult(char, obj, result, items)
+	}
+	return items
+}
+
+// rollResult бросает одну строку таблицы добычи и добавляет выпавшие предметы к items
+func (g *Game) rollResult(char *worldpkg.Character, obj *worldpkg.WorldObject, result config.InteractionResult, items []worldpkg.InventoryItem) []worldpkg.InventoryItem {
+	if !g.resultConditionsMet(char, obj, result) {
+		return items
+	}
+
+	// Проверяем шанс выпадения
+	if result.Chance > 0 && result.Chance < 1 && g.rand.Float64() >= result.Chance {
+		return items
+	}
+
+	// Взвешенная группа - выпадает один из вариантов
+	if len(result.OneOf) > 0 {
+		if choice := g.chooseWeighted(char, obj, result.OneOf); choice != nil {
+			items = g.rollResult(char, obj, *choice, items)
+		}
+		return items
+	}
+
+	count := result.Count
+	if result.Max > 0 {
+		count = g.RandomInt(result.Min, result.Max)
+	}
+
+	if result.ItemID > 0 && count > 0 {
+		items = append(items, worldpkg.InventoryItem{ItemID: result.ItemID, Count: count})
+	}
+	return items
+}
+
+// chooseWeighted выбирает вариант по весу среди тех, чьи условия выполнены
+func (g *Game) chooseWeighted(char *worldpkg.Character, obj *worldpkg.WorldObject, options []config.InteractionResult) *config.InteractionResult {
+	var candidates []*config.InteractionResult
+	totalWeight := 0
+	for i := range options {
+		if !g.resultConditionsMet(char, obj, options[i]) {
+			continue
+		}
+		candidates = append(candidates, &options[i])
+		totalWeight += resultWeight(options[i])
+	}
+
+	if totalWeight == 0 {
+		return nil
+	}
+
+	roll := g.rand.Intn(totalWeight)
+	for _, option := range candidates {
+		roll -= resultWeight(*option)
+		if roll < 0 {
+			return option
+		}
+	}
+	return nil
+}
+
+// resultWeight возвращает вес варианта (по умолчанию 1)
+func resultWeight(result config.InteractionResult) int {
+	if result.Weight <= 0 {
+		return 1
+	}
+	return result.Weight
+}
+
+// resultConditionsMet проверяет условия строки таблицы: инструмент и стадию роста объекта.
+// Без персонажа (char == nil) строки с условием на инструмент не выпадают.
+func (g *Game) resultConditionsMet(char *worldpkg.Character, obj *worldpkg.WorldObject, result config.InteractionResult) bool {
+	if result.Tool != "" && (char == nil || !g.HasTool(char, result.Tool)) {
+		return false
+	}
+
+	if obj != nil {
+		if obj.GrowthStage < result.MinGrowth {
+			return false
+		}
+		if result.MaxGrowth > 0 && obj.GrowthStage > result.MaxGrowth {
+			return false
+		}
+	}
+
+	return true
+}
+
+// printResults выводит таблицу добычи взаимодействия
+func (g *Game) printResults(results []config.InteractionResult, indent string) {
+	for _, result := range results {
+		if len(result.OneOf) > 0 {
+			fmt.Printf("%s-> одно из%s:\n", indent, describeResultConditions(result))
+			g.printResults(result.OneOf, indent+"   ")
+			continue
+		}
+
+		itemConfig := g.GetItemConfig(result.ItemID)
+		if itemConfig == nil {
+			continue
+		}
+
+		count := fmt.Sprintf("%d", result.Count)
+		if result.Max > 0 {
+			count = fmt.Sprintf("%d-%d", result.Min, result.Max)
+		}
+		fmt.Printf("%s-> %s x %s%s\n", indent, count, itemConfig.Name, describeResultConditions(result))
+	}
+}
+
+// describeResultConditions описывает шанс, вес и условия строки таблицы добычи
+func describeResultConditions(result config.InteractionResult) string {
+	desc := ""
+	if result.Chance > 0 && result.Chance < 1 {
+		desc += fmt.Sprintf(" шанс %.0f%%", result.Chance*100)
+	}
+	if result.Weight > 0 {
+		desc += fmt.Sprintf(" вес %d", result.Weight)
+	}
+	if result.Tool != "" {
+		desc += fmt.Sprintf(" (нужен %s)", result.Tool)
+	}
+	if result.MinGrowth > 0 || result.MaxGrowth > 0 {
+		maxGrowth := result.MaxGrowth
+		if maxGrowth == 0 {
+			maxGrowth = 100
+		}
+		desc += fmt.Sprintf(" (рост %d-%d)", result.MinGrowth, maxGrowth)
+	}
+	return desc
+}
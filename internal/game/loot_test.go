@@ -0,0 +1,270 @@
+package game
+
+import (
+	"LOIL-server/internal/config"
+	worldpkg "LOIL-server/internal/world"
+	"reflect"
+	"testing"
+)
+
+// Предметы из встроенного item_types.json
+const (
+	testItemMushroom  = 1
+	testItemBranch    = 3
+	testItemStone     = 4
+	testItemRaspberry = 5
+	testItemAcorn     = 7
+	testItemAxe       = 8
+)
+
+// testConfigs загружает встроенные конфигурации
+func testConfigs(t *testing.T) *config.Configs {
+	t.Helper()
+
+	configs, err := config.LoadConfigs("")
+	if err != nil {
+		t.Fatalf("LoadConfigs: %v", err)
+	}
+	return configs
+}
+
+// newLootGame создает игру без локаций - для бросков добычи нужны только конфиги и генератор
+func newLootGame(t *testing.T, seed int64) *Game {
+	t.Helper()
+
+	g := NewGame(&worldpkg.World{Configs: testConfigs(t)})
+	g.SetSeed(seed)
+	return g
+}
+
+// drop - выпавшие за один бросок предметы
+func drop(pairs ...int) []worldpkg.InventoryItem {
+	var items []worldpkg.InventoryItem
+	for i := 0; i+1 < len(pairs); i += 2 {
+		items = append(items, worldpkg.InventoryItem{ItemID: pairs[i], Count: pairs[i+1]})
+	}
+	return items
+}
+
+func TestRollResults(t *testing.T) {
+	bareHands := func() *worldpkg.Character {
+		return &worldpkg.Character{HandsFree: true, Equipped: map[string]int{}}
+	}
+	withAxe := func() *worldpkg.Character {
+		return &worldpkg.Character{HandsFree: false, Equipped: map[string]int{"axe": testItemAxe}}
+	}
+	atStage := func(stage int) *worldpkg.WorldObject {
+		return &worldpkg.WorldObject{GrowthStage: stage}
+	}
+
+	tests := []struct {
+		name    string
+		results []config.InteractionResult
+		char    *worldpkg.Character
+		obj     *worldpkg.WorldObject
+		want    [][]worldpkg.InventoryItem // Результат каждого броска подряд при seed 42
+	}{
+		{
+			name:    "фиксированное количество",
+			results: []config.InteractionResult{{ItemID: testItemRaspberry, Count: 3}},
+			char:    bareHands(),
+			want:    [][]worldpkg.InventoryItem{drop(testItemRaspberry, 3), drop(testItemRaspberry, 3)},
+		},
+		{
+			name:    "диапазон min..max",
+			results: []config.InteractionResult{{ItemID: testItemRaspberry, Min: 2, Max: 4}},
+			char:    bareHands(),
+			want: [][]worldpkg.InventoryItem{
+				drop(testItemRaspberry, 4), drop(testItemRaspberry, 4), drop(testItemRaspberry, 4),
+				drop(testItemRaspberry, 2), drop(testItemRaspberry, 3), drop(testItemRaspberry, 3),
+			},
+		},
+		{
+			name:    "шанс 0 и 1 - выпадает всегда",
+			results: []config.InteractionResult{{ItemID: testItemStone, Count: 1}, {ItemID: testItemBranch, Count: 2, Chance: 1}},
+			char:    bareHands(),
+			want:    [][]worldpkg.InventoryItem{drop(testItemStone, 1, testItemBranch, 2), drop(testItemStone, 1, testItemBranch, 2)},
+		},
+		{
+			name:    "шанс 0.5",
+			results: []config.InteractionResult{{ItemID: testItemStone, Count: 1, Chance: 0.5}},
+			char:    bareHands(),
+			want: [][]worldpkg.InventoryItem{
+				drop(testItemStone, 1), drop(testItemStone, 1), nil,
+				drop(testItemStone, 1), drop(testItemStone, 1), drop(testItemStone, 1),
+			},
+		},
+		{
+			name: "взвешенная группа one_of",
+			results: []config.InteractionResult{{OneOf: []config.InteractionResult{
+				{ItemID: testItemAcorn, Count: 1, Weight: 3},
+				{ItemID: testItemBranch, Count: 1, Weight: 1},
+			}}},
+			char: bareHands(),
+			want: [][]worldpkg.InventoryItem{
+				drop(testItemAcorn, 1), drop(testItemBranch, 1), drop(testItemAcorn, 1),
+				drop(testItemAcorn, 1), drop(testItemBranch, 1), drop(testItemAcorn, 1),
+			},
+		},
+		{
+			name: "one_of без подходящего по условию варианта",
+			results: []config.InteractionResult{{OneOf: []config.InteractionResult{
+				{ItemID: testItemAcorn, Count: 1, Weight: 100, Tool: "axe"},
+				{ItemID: testItemBranch, Count: 1, Weight: 1},
+			}}},
+			char: bareHands(),
+			want: [][]worldpkg.InventoryItem{drop(testItemBranch, 1), drop(testItemBranch, 1), drop(testItemBranch, 1)},
+		},
+		{
+			name:    "условие на инструмент не выполнено",
+			results: []config.InteractionResult{{ItemID: testItemBranch, Count: 2, Tool: "axe"}, {ItemID: testItemAcorn, Count: 1}},
+			char:    bareHands(),
+			want:    [][]worldpkg.InventoryItem{drop(testItemAcorn, 1)},
+		},
+		{
+			name:    "условие на инструмент выполнено",
+			results: []config.InteractionResult{{ItemID: testItemBranch, Count: 2, Tool: "axe"}, {ItemID: testItemAcorn, Count: 1}},
+			char:    withAxe(),
+			want:    [][]worldpkg.InventoryItem{drop(testItemBranch, 2, testItemAcorn, 1)},
+		},
+		{
+			name:    "условие hand с инструментом в руках",
+			results: []config.InteractionResult{{ItemID: testItemMushroom, Count: 1, Tool: config.HandTool}},
+			char:    withAxe(),
+			want:    [][]worldpkg.InventoryItem{nil},
+		},
+		{
+			name:    "без персонажа строки с инструментом не выпадают",
+			results: []config.InteractionResult{{ItemID: testItemBranch, Count: 2, Tool: config.HandTool}, {ItemID: testItemAcorn, Count: 1}},
+			want:    [][]worldpkg.InventoryItem{drop(testItemAcorn, 1)},
+		},
+		{
+			name:    "стадия роста ниже min_growth",
+			results: []config.InteractionResult{{ItemID: testItemMushroom, Count: 1, MinGrowth: 50}},
+			char:    bareHands(),
+			obj:     atStage(49),
+			want:    [][]worldpkg.InventoryItem{nil},
+		},
+		{
+			name:    "стадия роста равна min_growth",
+			results: []config.InteractionResult{{ItemID: testItemMushroom, Count: 1, MinGrowth: 50}},
+			char:    bareHands(),
+			obj:     atStage(50),
+			want:    [][]worldpkg.InventoryItem{drop(testItemMushroom, 1)},
+		},
+		{
+			name:    "стадия роста выше max_growth",
+			results: []config.InteractionResult{{ItemID: testItemMushroom, Count: 1, MaxGrowth: 80}},
+			char:    bareHands(),
+			obj:     atStage(81),
+			want:    [][]worldpkg.InventoryItem{nil},
+		},
+		{
+			name:    "условия роста без объекта не проверяются",
+			results: []config.InteractionResult{{ItemID: testItemMushroom, Count: 1, MinGrowth: 50}},
+			char:    bareHands(),
+			want:    [][]worldpkg.InventoryItem{drop(testItemMushroom, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newLootGame(t, 42)
+			for i, want := range tt.want {
+				got := g.RollResults(tt.char, tt.obj, tt.results)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("бросок %d: получили %v, ожидали %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRollResultsRange(t *testing.T) {
+	g := newLootGame(t, 1)
+	results := []config.InteractionResult{{ItemID: testItemRaspberry, Min: 2, Max: 4}}
+
+	seen := make(map[int]int)
+	for i := 0; i < 300; i++ {
+		items := g.RollResults(nil, nil, results)
+		if len(items) != 1 {
+			t.Fatalf("ожидали одну стопку, получили %v", items)
+		}
+		count := items[0].Count
+		if count < 2 || count > 4 {
+			t.Fatalf("количество %d вне диапазона 2..4", count)
+		}
+		seen[count]++
+	}
+
+	for count := 2; count <= 4; count++ {
+		if seen[count] == 0 {
+			t.Errorf("количество %d ни разу не выпало за 300 бросков", count)
+		}
+	}
+}
+
+func TestRollResultsSeedRepeats(t *testing.T) {
+	results := []config.InteractionResult{
+		{ItemID: testItemRaspberry, Min: 1, Max: 10},
+		{ItemID: testItemStone, Count: 1, Chance: 0.3},
+		{OneOf: []config.InteractionResult{{ItemID: testItemAcorn, Count: 1}, {ItemID: testItemBranch, Count: 1}}},
+	}
+
+	a, b := newLootGame(t, 7), newLootGame(t, 7)
+	for i := 0; i < 50; i++ {
+		gotA, gotB := a.RollResults(nil, nil, results), b.RollResults(nil, nil, results)
+		if !reflect.DeepEqual(gotA, gotB) {
+			t.Fatalf("бросок %d: одинаковый seed дал разные результаты %v и %v", i, gotA, gotB)
+		}
+	}
+}
+
+// testObjectRaspberryBush - куст малины из встроенного object_types.json
+const testObjectRaspberryBush = 5
+
+func TestRaspberryBushHarvestTable(t *testing.T) {
+	g := newLootGame(t, 42)
+	objConfig := g.GetObjectConfig(testObjectRaspberryBush)
+	if objConfig == nil || len(objConfig.Interactions) == 0 {
+		t.Fatal("у куста малины нет взаимодействий")
+	}
+	results := objConfig.Interactions[0].Results
+
+	// 2..4 малины и ветка с шансом 0.2
+	want := [][]worldpkg.InventoryItem{
+		drop(testItemRaspberry, 4, testItemBranch, 1),
+		drop(testItemRaspberry, 4),
+		drop(testItemRaspberry, 3),
+		drop(testItemRaspberry, 2),
+		drop(testItemRaspberry, 4),
+		drop(testItemRaspberry, 3),
+		drop(testItemRaspberry, 2, testItemBranch, 1),
+		drop(testItemRaspberry, 4),
+	}
+	for i, items := range want {
+		if got := g.RollResults(nil, nil, results); !reflect.DeepEqual(got, items) {
+			t.Errorf("сбор %d: получили %v, ожидали %v", i, got, items)
+		}
+	}
+}
+
+func TestRaspberryBushHarvest(t *testing.T) {
+	// Две одинаковые игры: в одной куст собирается, в другой та же таблица бросается напрямую
+	harvested, char, bushID := newObjectGame(t, testObjectRaspberryBush)
+	rolled, _, _ := newObjectGame(t, testObjectRaspberryBush)
+
+	results := rolled.GetObjectConfig(testObjectRaspberryBush).Interactions[0].Results
+	want := rolled.RollResults(char, harvested.GameWorld.Objects[bushID], results)
+
+	outcome := harvested.PerformInteractionByIndex(char, bushID, 0)
+	if !outcome.Success {
+		t.Fatalf("сбор не удался: %s", outcome.Message)
+	}
+	if !reflect.DeepEqual(outcome.Items, want) {
+		t.Errorf("собрано %v, ожидали %v", outcome.Items, want)
+	}
+	if outcome.Object == nil || outcome.Object.Durability != 20 {
+		t.Errorf("куст после сбора: %+v, ожидали прочность 20", outcome.Object)
+	}
+}
@@ -0,0 +1,35 @@
+package game
+
+import (
+	"LOIL-server/internal/metrics"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// tickBuckets - границы корзин длительности тика в секундах (тик идет раз в 16 мс)
+var tickBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.016, 0.025, 0.05, 0.1}
+
+// newTickDuration создает гистограмму длительности обработки тика
+func newTickDuration() *metrics.Histogram {
+	return metrics.NewHistogram("loil_tick_duration_seconds", "Длительность обработки тика игрового цикла", tickBuckets)
+}
+
+// RegisterMetrics добавляет метрики игры в реестр: длительность тика и число сущностей по локациям
+func (g *Game) RegisterMetrics(registry *metrics.Registry) {
+	registry.Register(g.tickDuration, metrics.NewGaugeFunc("loil_location_entities",
+		"Сущности в локации по виду (из последнего снимка мира)", []string{"location", "kind"},
+		func(emit func(value float64, labelValues ...string)) {
+			snap := g.Snapshot()
+			if snap == nil {
+				return
+			}
+			for _, id := range slices.Sorted(maps.Keys(snap.Locations)) {
+				loc := snap.Locations[id]
+				location := strconv.Itoa(id)
+				emit(float64(len(loc.Characters)), location, "character")
+				emit(float64(len(loc.Creatures)), location, "creature")
+				emit(float64(len(loc.Objects)), location, "object")
+			}
+		}))
+}
@@ -3,12 +3,15 @@ package game
 
 import (
 	"LOIL-server/internal/network"
+	"errors"
+	"math"
 	"time"
 )
 
 // GameNetworkBridge реализует интерфейс network.GameStateProvider
 type GameNetworkBridge struct {
-	Game *Game
+	Game   *Game
+	Server *network.Server
 }
 
 // NewGameNetworkBridge создает мост между игрой и сетью
@@ -16,144 +19,337 @@ func NewGameNetworkBridge(game *Game) *GameNetworkBridge {
 	return &GameNetworkBridge{Game: game}
 }
 
-// GetLocationState возвращает состояние локации для сети
-func (b *GameNetworkBridge) GetLocationState(locationID int) *network.LocationState {
-	locState := b.Game.State.LocationStates[locationID]
-	if locState == nil {
-		return nil
+// Attach подключает сервер для доставки игровых событий клиентам
+func (b *GameNetworkBridge) Attach(server *network.Server) {
+	b.Server = server
+	b.Game.OnEvent = b.handleEvent
+}
+
+// handleEvent преобразует игровое событие в сетевое сообщение (вызывается из игрового цикла)
+func (b *GameNetworkBridge) handleEvent(event GameEvent) {
+	if b.Server == nil {
+		return
 	}
 
-	loc := b.Game.GetLocation(locationID)
-	if loc == nil {
-		return nil
+	switch event.Type {
+	case EventActionStarted, EventActionProgress, EventActionCompleted:
+		b.sendToController(event.CharacterID, network.MessageType(event.Type), b.actionToNetwork(event.Payload.(*ActionEvent)))
+	case EventCombat:
+		combat := event.Payload.(*CombatEvent)
+		b.Server.SendToLocation(event.LocationID, network.MsgCombat, &network.CombatUpdate{
+			LocationID:   event.LocationID,
+			AttackerKind: combat.AttackerKind,
+			AttackerID:   combat.AttackerID,
+			TargetKind:   combat.TargetKind,
+			TargetID:     combat.TargetID,
+			Damage:       combat.Damage,
+			Health:       combat.Health,
+			MaxHealth:    combat.MaxHealth,
+			Killed:       combat.Killed,
+			ServerTime:   time.Now().UnixMilli(),
+		})
+	case EventCharacterDied:
+		death := event.Payload.(*CharacterDeathEvent)
+		b.Server.SendToLocation(event.LocationID, network.MsgCharacterDied, &network.CharacterDied{
+			LocationID:  event.LocationID,
+			CharacterID: death.CharacterID,
+			Name:        death.Name,
+			X:           death.X,
+			Cause:       death.Cause,
+			KillerID:    death.KillerID,
+			ContainerID: death.ContainerID,
+			ServerTime:  time.Now().UnixMilli(),
+		})
+	case EventInventoryChanged:
+		if char := b.Game.GetCharacterByID(event.CharacterID); char != nil {
+			b.sendToController(char.ID, network.MsgInventoryUpdate, b.inventoryToNetwork(char))
+		}
+	case EventCreatureDied:
+		death := event.Payload.(*CreatureDeathEvent)
+		b.Server.SendToLocation(event.LocationID, network.MsgCreatureDied, &network.CreatureDied{
+			LocationID:  event.LocationID,
+			CreatureID:  death.CreatureID,
+			TypeID:      death.TypeID,
+			Name:        death.Name,
+			X:           death.X,
+			KillerKind:  death.KillerKind,
+			KillerID:    death.KillerID,
+			ContainerID: death.ContainerID,
+			ServerTime:  time.Now().UnixMilli(),
+		})
 	}
+}
 
-	return &network.LocationState{
-		ID:         loc.ID,
-		Name:       loc.Name,
-		Width:      len(locState.Road),
-		Foreground: locState.Foreground,
-		Road:       locState.Road,
-		Ground:     locState.Ground,
-		Background: locState.Background,
-		LastUpdate: time.Now().UnixMilli(),
+// sendToController отправляет сообщение игроку, управляющему персонажем
+func (b *GameNetworkBridge) sendToController(characterID int, msgType network.MessageType, payload interface{}) {
+	char := b.Game.GetCharacterByID(characterID)
+	if char == nil || char.Controlled == 0 {
+		return
 	}
+	b.Server.SendToPlayer(char.Controlled, msgType, payload)
 }
 
-// GetCharactersInLocation возвращает персонажей в локации
-func (b *GameNetworkBridge) GetCharactersInLocation(locationID int) []*network.CharacterState {
-	chars, ok := b.Game.State.CharsByLocation[locationID]
-	if !ok {
+// GetLocationState возвращает состояние локации для сети
+func (b *GameNetworkBridge) GetLocationState(locationID int) *network.LocationState {
+	locSnap := b.locationSnapshot(locationID)
+	if locSnap == nil {
 		return nil
 	}
+	return b.locationToNetwork(locSnap)
+}
 
-	result := make([]*network.CharacterState, 0, len(chars))
-	for _, char := range chars {
-		result = append(result, b.characterToNetwork(char))
+// GetCharactersInLocation возвращает персонажей в локации
+func (b *GameNetworkBridge) GetCharactersInLocation(locationID int) []*network.CharacterState {
+	locSnap := b.locationSnapshot(locationID)
+	if locSnap == nil {
+		return nil
 	}
-
-	return result
+	return b.charactersToNetwork(locSnap)
 }
 
 // GetCreaturesInLocation возвращает существ в локации
 func (b *GameNetworkBridge) GetCreaturesInLocation(locationID int) []*network.CreatureState {
-	creatures, ok := b.Game.State.CreaturesByLocation[locationID]
-	if !ok {
+	locSnap := b.locationSnapshot(locationID)
+	if locSnap == nil {
 		return nil
 	}
-
-	result := make([]*network.CreatureState, 0, len(creatures))
-	for _, creature := range creatures {
-		result = append(result, b.creatureToNetwork(creature))
-	}
-
-	return result
+	return b.creaturesToNetwork(locSnap)
 }
 
 // GetObjectsInLocation возвращает объекты в локации
 func (b *GameNetworkBridge) GetObjectsInLocation(locationID int) []*network.ObjectState {
-	objects, ok := b.Game.State.ObjectsByLocation[locationID]
-	if !ok {
+	locSnap := b.locationSnapshot(locationID)
+	if locSnap == nil {
 		return nil
 	}
+	return b.objectsToNetwork(locSnap)
+}
 
-	result := make([]*network.ObjectState, 0, len(objects))
-	for _, obj := range objects {
-		result = append(result, b.objectToNetwork(obj))
+// GetLocationSnapshot возвращает согласованное состояние локации из одного тика
+func (b *GameNetworkBridge) GetLocationSnapshot(locationID int) *network.LocationSnapshot {
+	snap := b.Game.Snapshot()
+	if snap == nil {
+		return nil
 	}
 
-	return result
+	locSnap := snap.Locations[locationID]
+	if locSnap == nil {
+		return nil
+	}
+
+	return &network.LocationSnapshot{
+		Location:   b.locationToNetwork(locSnap),
+		Characters: b.charactersToNetwork(locSnap),
+		Creatures:  b.creaturesToNetwork(locSnap),
+		Objects:    b.objectsToNetwork(locSnap),
+		ServerTime: snap.Time.UnixMilli(),
+	}
 }
 
 // GetCharacterByID возвращает персонажа по ID
 func (b *GameNetworkBridge) GetCharacterByID(characterID int) *network.CharacterState {
-	char := b.Game.GetPlayerCharacter() // TODO: Нужен метод получения по ID
-	if char != nil && char.ID == characterID {
-		return b.characterToNetwork(char)
+	var state *network.CharacterState
+	b.Game.Do(func(g *Game) {
+		if char := g.GetCharacterByID(characterID); char != nil {
+			state = b.characterToNetwork(char)
+		}
+	})
+	return state
+}
+
+// GetCharacterPosition возвращает локацию и позицию персонажа из последнего снимка
+func (b *GameNetworkBridge) GetCharacterPosition(characterID int) (int, float64, bool) {
+	snap := b.Game.Snapshot()
+	if snap == nil {
+		return 0, 0, false
 	}
-	return nil
+
+	char, ok := snap.Characters[characterID]
+	if !ok {
+		return 0, 0, false
+	}
+	return char.Location, char.X, true
 }
 
 // HandleJoin обрабатывает присоединение игрока
 func (b *GameNetworkBridge) HandleJoin(playerID, characterID, locationID int) (*network.CharacterState, error) {
-	// TODO: Реализовать логику присоединения
-	// Пока просто возвращаем первого персонажа
-	char := b.Game.GetPlayerCharacter()
-	if char == nil {
-		return nil, network.NewError("no_character", "Персонаж не найден")
+	var state *network.CharacterState
+	var err error
+	if !b.Game.Do(func(g *Game) {
+		var char *Character
+		char, err = g.JoinPlayer(playerID, characterID, locationID)
+		if err == nil {
+			state = b.characterToNetwork(char)
+		}
+	}) {
+		err = ErrGameStopped
+	}
+	if err != nil {
+		return nil, toNetworkError(err)
 	}
 
-	return b.characterToNetwork(char), nil
+	return state, nil
+}
+
+// HandleLeave обрабатывает отключение игрока
+func (b *GameNetworkBridge) HandleLeave(playerID int) {
+	b.Game.Enqueue(func(g *Game) {
+		g.LeavePlayer(playerID)
+	})
+}
+
+// HandleDisconnect останавливает персонажа игрока, соединение которого оборвалось.
+// Персонаж остается за игроком на время ожидания возобновления сессии.
+func (b *GameNetworkBridge) HandleDisconnect(playerID int) {
+	b.Game.Enqueue(func(g *Game) {
+		g.SuspendPlayer(playerID)
+	})
 }
 
 // HandleMove обрабатывает движение
 func (b *GameNetworkBridge) HandleMove(playerID int, direction, vertical int) error {
-	char := b.Game.GetPlayerCharacter()
-	if char == nil {
-		return network.NewError("no_character", "Персонаж не найден")
-	}
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		// Преобразуем команду в формат игры
+		if direction == 0 {
+			char.Direction = 0
+			char.Vertical = 0
+		} else {
+			char.Direction = direction
+			char.Vertical = vertical
+		}
+		return nil
+	})
+}
 
-	// Преобразуем команду в формат игры
-	if direction == 0 {
+// HandleStop обрабатывает остановку
+func (b *GameNetworkBridge) HandleStop(playerID int) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
 		char.Direction = 0
 		char.Vertical = 0
-	} else {
-		char.Direction = direction
-		char.Vertical = vertical
+		return nil
+	})
+}
+
+// HandleInteract обрабатывает взаимодействие (тот же путь, что и консольная команда act):
+// interactionIdx - номер среди доступных персонажу действий объекта.
+// Для длительных взаимодействий результат равен nil.
+func (b *GameNetworkBridge) HandleInteract(playerID int, objectID, interactionIdx int) (*network.InteractionResult, error) {
+	var result *network.InteractionResult
+	err := b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		// Длительное действие начинается, результат придет в action_completed
+		if outcome := g.StartInteraction(char, objectID, interactionIdx); outcome != nil {
+			result = b.interactionToNetwork(outcome)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
 
-// HandleStop обрабатывает остановку
-func (b *GameNetworkBridge) HandleStop(playerID int) error {
-	char := b.Game.GetPlayerCharacter()
-	if char == nil {
-		return network.NewError("no_character", "Персонаж не найден")
+// HandleAttack обрабатывает удар персонажа игрока по существу
+func (b *GameNetworkBridge) HandleAttack(playerID int, creatureID int) (*network.InteractionResult, error) {
+	var result *network.InteractionResult
+	err := b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		result = b.interactionToNetwork(g.AttackCreature(char, creatureID))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	char.Direction = 0
-	char.Vertical = 0
+	return result, nil
+}
 
-	return nil
+// HandleInventoryMove перекладывает предмет в инвентаре персонажа игрока
+func (b *GameNetworkBridge) HandleInventoryMove(playerID int, from, to int) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		return g.MoveItem(char, from, to)
+	})
 }
 
-// HandleInteract обрабатывает взаимодействие
-func (b *GameNetworkBridge) HandleInteract(playerID int, objectID, interactionIdx int) (*network.InteractionResult, error) {
-	char := b.Game.GetPlayerCharacter()
-	if char == nil {
-		return nil, network.NewError("no_character", "Персонаж не найден")
-	}
+// HandleInventorySplit разделяет стопку в инвентаре персонажа игрока
+func (b *GameNetworkBridge) HandleInventorySplit(playerID int, from, to, count int) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		return g.SplitStack(char, from, to, count)
+	})
+}
+
+// HandleInventoryDrop выкладывает предметы персонажа игрока на землю
+func (b *GameNetworkBridge) HandleInventoryDrop(playerID int, slot, count int) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		_, err := g.DropItem(char, slot, count)
+		return err
+	})
+}
 
-	// TODO: Реализовать взаимодействие через существующую логику
-	// b.Game.PerformInteractionByIndex(char, objectID, interactionIdx)
+// HandleEquip берет в руки персонажа игрока инструмент из слота
+func (b *GameNetworkBridge) HandleEquip(playerID int, slot int) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		return g.Equip(char, slot)
+	})
+}
 
-	return &network.InteractionResult{
-		Success:    true,
-		ObjectID:   objectID,
-		Message:    "Взаимодействие выполнено",
-		ServerTime: time.Now().UnixMilli(),
-	}, nil
+// HandleUnequip убирает инструмент персонажа игрока в инвентарь
+func (b *GameNetworkBridge) HandleUnequip(playerID int, toolKind string) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		return g.Unequip(char, toolKind)
+	})
+}
+
+// GetInventory возвращает содержимое инвентаря персонажа
+func (b *GameNetworkBridge) GetInventory(characterID int) *network.InventoryUpdate {
+	var inventory *network.InventoryUpdate
+	b.Game.Do(func(g *Game) {
+		if char := g.GetCharacterByID(characterID); char != nil {
+			inventory = b.inventoryToNetwork(char)
+		}
+	})
+	return inventory
+}
+
+// HandleEat обрабатывает еду из слота инвентаря
+func (b *GameNetworkBridge) HandleEat(playerID int, slot int) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		return g.Eat(char, slot)
+	})
+}
+
+// HandleDrink обрабатывает питье
+func (b *GameNetworkBridge) HandleDrink(playerID int) error {
+	return b.doForPlayer(playerID, func(g *Game, char *Character) error {
+		return g.Drink(char)
+	})
+}
+
+// doForPlayer выполняет действие над персонажем игрока внутри игрового цикла
+func (b *GameNetworkBridge) doForPlayer(playerID int, action func(g *Game, char *Character) error) error {
+	var err error
+	if !b.Game.Do(func(g *Game) {
+		char := g.GetCharacterForPlayer(playerID)
+		if char == nil {
+			err = ErrNotJoined
+			return
+		}
+		err = action(g, char)
+	}) {
+		err = ErrGameStopped
+	}
+	if err != nil {
+		return toNetworkError(err)
+	}
+	return nil
+}
+
+// locationSnapshot возвращает снимок локации из последнего тика
+func (b *GameNetworkBridge) locationSnapshot(locationID int) *LocationSnapshot {
+	snap := b.Game.Snapshot()
+	if snap == nil {
+		return nil
+	}
+	return snap.Locations[locationID]
 }
 
 // GetServerTime возвращает время сервера
@@ -163,25 +359,114 @@ func (b *GameNetworkBridge) GetServerTime() int64 {
 
 // GetLocationName возвращает название локации
 func (b *GameNetworkBridge) GetLocationName(locationID int) string {
-	loc := b.Game.GetLocation(locationID)
-	if loc != nil {
-		return loc.Name
+	locSnap := b.locationSnapshot(locationID)
+	if locSnap != nil {
+		return locSnap.Name
 	}
 	return ""
 }
 
+// toNetworkError преобразует ошибку игры в сетевую ошибку с кодом
+func toNetworkError(err error) error {
+	switch {
+	case errors.Is(err, ErrAlreadyJoined):
+		return network.NewError("already_joined", err.Error())
+	case errors.Is(err, ErrNotJoined):
+		return network.NewError("not_joined", err.Error())
+	case errors.Is(err, ErrCharacterNotFound):
+		return network.NewError("no_character", err.Error())
+	case errors.Is(err, ErrCharacterTaken):
+		return network.NewError("character_taken", err.Error())
+	case errors.Is(err, ErrCharacterNotOwned):
+		return network.NewError("not_owner", err.Error())
+	case errors.Is(err, ErrLocationNotFound):
+		return network.NewError("location_not_found", err.Error())
+	case errors.Is(err, ErrInvalidSlot):
+		return network.NewError("invalid_slot", err.Error())
+	case errors.Is(err, ErrEmptySlot):
+		return network.NewError("empty_slot", err.Error())
+	case errors.Is(err, ErrSlotOccupied):
+		return network.NewError("slot_occupied", err.Error())
+	case errors.Is(err, ErrInvalidCount):
+		return network.NewError("invalid_count", err.Error())
+	case errors.Is(err, ErrStackFull):
+		return network.NewError("stack_full", err.Error())
+	case errors.Is(err, ErrCannotDrop):
+		return network.NewError("cannot_drop", err.Error())
+	case errors.Is(err, ErrNotTool):
+		return network.NewError("not_tool", err.Error())
+	case errors.Is(err, ErrNothingEquipped):
+		return network.NewError("nothing_equipped", err.Error())
+	case errors.Is(err, ErrInventoryFull):
+		return network.NewError("inventory_full", err.Error())
+	case errors.Is(err, ErrNotFood):
+		return network.NewError("not_food", err.Error())
+	case errors.Is(err, ErrNoWaterNear):
+		return network.NewError("no_water", err.Error())
+	case errors.Is(err, ErrGameStopped):
+		return network.NewError("game_stopped", err.Error())
+	}
+	return err
+}
+
 // Вспомогательные методы преобразования
+func (b *GameNetworkBridge) locationToNetwork(locSnap *LocationSnapshot) *network.LocationState {
+	return &network.LocationState{
+		ID:         locSnap.ID,
+		Name:       locSnap.Name,
+		Width:      len(locSnap.Road),
+		Foreground: locSnap.Foreground,
+		Road:       locSnap.Road,
+		Ground:     locSnap.Ground,
+		Background: locSnap.Background,
+		LastUpdate: time.Now().UnixMilli(),
+	}
+}
+
+func (b *GameNetworkBridge) charactersToNetwork(locSnap *LocationSnapshot) []*network.CharacterState {
+	result := make([]*network.CharacterState, 0, len(locSnap.Characters))
+	for _, char := range locSnap.Characters {
+		result = append(result, b.characterToNetwork(char))
+	}
+	return result
+}
+
+func (b *GameNetworkBridge) creaturesToNetwork(locSnap *LocationSnapshot) []*network.CreatureState {
+	result := make([]*network.CreatureState, 0, len(locSnap.Creatures))
+	for _, creature := range locSnap.Creatures {
+		result = append(result, b.creatureToNetwork(creature))
+	}
+	return result
+}
+
+func (b *GameNetworkBridge) objectsToNetwork(locSnap *LocationSnapshot) []*network.ObjectState {
+	result := make([]*network.ObjectState, 0, len(locSnap.Objects))
+	for _, obj := range locSnap.Objects {
+		result = append(result, b.objectToNetwork(obj))
+	}
+	return result
+}
+
 func (b *GameNetworkBridge) characterToNetwork(char *Character) *network.CharacterState {
-	return &network.CharacterState{
+	state := &network.CharacterState{
 		ID:         char.ID,
 		Name:       char.Name,
 		LocationID: char.Location,
 		X:          char.X,
 		Direction:  char.Direction,
 		Speed:      char.Speed,
+		Health:     char.Health,
+		MaxHealth:  char.MaxHealth,
+		Hunger:     int(math.Round(char.Hunger)),
+		Thirst:     int(math.Round(char.Thirst)),
+		Stamina:    int(math.Round(char.Stamina)),
 		Controlled: char.Controlled,
 		LastUpdate: time.Now().UnixMilli(),
 	}
+	if char.Action != nil {
+		state.Action = char.Action.Type
+	}
+	return state
 }
 
 func (b *GameNetworkBridge) creatureToNetwork(creature *Creature) *network.CreatureState {
@@ -204,6 +489,93 @@ func (b *GameNetworkBridge) creatureToNetwork(creature *Creature) *network.Creat
 	}
 }
 
+// interactionToNetwork вызывается внутри игрового цикла, пока объект еще не изменился
+func (b *GameNetworkBridge) interactionToNetwork(outcome *InteractionOutcome) *network.InteractionResult {
+	result := &network.InteractionResult{
+		Success:       outcome.Success,
+		Code:          string(outcome.Failure),
+		ObjectID:      outcome.ObjectID,
+		Interaction:   outcome.Interaction,
+		Message:       outcome.Message,
+		Destroyed:     outcome.Destroyed,
+		TransformedTo: outcome.TransformedTo,
+		CreatureID:    outcome.CreatureID,
+		Damage:        outcome.Damage,
+		Killed:        outcome.Killed,
+		ContainerID:   outcome.ContainerID,
+		ServerTime:    time.Now().UnixMilli(),
+	}
+
+	if outcome.Success && result.Message == "" {
+		result.Message = "Взаимодействие выполнено"
+	}
+
+	for _, item := range outcome.Items {
+		result.Items = append(result.Items, b.itemToNetwork(item))
+	}
+
+	if outcome.Object != nil && !outcome.Destroyed {
+		result.Object = b.objectToNetwork(outcome.Object)
+	}
+	if outcome.Creature != nil && !outcome.Killed {
+		result.Creature = b.creatureToNetwork(outcome.Creature)
+	}
+
+	return result
+}
+
+// inventoryToNetwork вызывается внутри игрового цикла
+func (b *GameNetworkBridge) inventoryToNetwork(char *Character) *network.InventoryUpdate {
+	inventory := &network.InventoryUpdate{
+		CharacterID: char.ID,
+		Size:        b.Game.InventorySize,
+		Slots:       make(map[int]network.InventoryItem, len(char.Inventory)),
+		Equipped:    make(map[string]network.InventoryItem, len(char.Equipped)),
+		HandsFree:   char.HandsFree,
+		ServerTime:  time.Now().UnixMilli(),
+	}
+	for slot, item := range char.Inventory {
+		inventory.Slots[slot] = b.itemToNetwork(item)
+	}
+	for toolKind, itemID := range char.Equipped {
+		inventory.Equipped[toolKind] = b.itemToNetwork(InventoryItem{ItemID: itemID, Count: 1})
+	}
+	return inventory
+}
+
+// itemToNetwork добавляет к предмету название из конфига
+func (b *GameNetworkBridge) itemToNetwork(item InventoryItem) network.InventoryItem {
+	netItem := network.InventoryItem{
+		ItemID: item.ItemID,
+		Count:  item.Count,
+	}
+	if itemConfig := b.Game.GetItemConfig(item.ItemID); itemConfig != nil {
+		netItem.Name = itemConfig.Name
+	}
+	return netItem
+}
+
+func (b *GameNetworkBridge) actionToNetwork(event *ActionEvent) *network.ActionState {
+	state := &network.ActionState{
+		CharacterID:    event.CharacterID,
+		Action:         event.Type,
+		ObjectID:       event.ObjectID,
+		InteractionIdx: event.InteractionIndex,
+		Duration:       event.Duration,
+		Elapsed:        event.Elapsed,
+		Cancelled:      event.Cancelled,
+		Reason:         event.Reason,
+		ServerTime:     time.Now().UnixMilli(),
+	}
+	if event.Duration > 0 {
+		state.Progress = min(1, event.Elapsed/event.Duration)
+	}
+	if event.Outcome != nil {
+		state.Result = b.interactionToNetwork(event.Outcome)
+	}
+	return state
+}
+
 func (b *GameNetworkBridge) objectToNetwork(obj *WorldObject) *network.ObjectState {
 	objConfig := b.Game.GetObjectConfig(obj.TypeID)
 	maxDurability := 0
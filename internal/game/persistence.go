@@ -0,0 +1,182 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// SaveFileName - имя файла сохранения в каталоге сохранений
+	SaveFileName = "world.json"
+	// DefaultSaveBackups - сколько резервных копий сохранения хранить по умолчанию
+	DefaultSaveBackups = 5
+)
+
+// SaveFile возвращает путь к файлу сохранения
+func (g *Game) SaveFile() string {
+	return filepath.Join(g.SaveDir, SaveFileName)
+}
+
+// MarshalSave сериализует текущее состояние мира для сохранения.
+// Вызывается только из игрового цикла, поэтому снимок согласован.
+func (g *Game) MarshalSave() ([]byte, error) {
+	saveWorld := &worldpkg.World{
+		FormatVersion: worldpkg.CurrentFormatVersion,
+		PlayerID:      g.GameWorld.PlayerID,
+		Characters:    make([]*worldpkg.Character, 0, len(g.GameWorld.Characters)),
+		Locations:     make([]*worldpkg.Location, 0, len(g.GameWorld.Locations)),
+		Objects:       g.GameWorld.Objects,
+		Creatures:     g.GameWorld.Creatures,
+		SavedAt:       g.Clock.Now(),
+		Tick:          g.GameWorld.Tick,
+	}
+
+	for _, char := range g.GameWorld.Characters {
+		charCopy := copyCharacter(char)
+		// Сетевые игроки после перезапуска подключаются заново
+		if char.Controlled != 0 && g.Players.Get(char.Controlled) == char {
+			charCopy.Controlled = 0
+		}
+		saveWorld.Characters = append(saveWorld.Characters, charCopy)
+	}
+
+	for _, loc := range g.GameWorld.Locations {
+		saveWorld.Locations = append(saveWorld.Locations, g.saveLocation(loc))
+	}
+
+	return json.MarshalIndent(saveWorld, "", "  ")
+}
+
+// saveLocation создает копию локации с текущими слоями.
+// Все объекты сохраняются в общем списке мира, поэтому у копии он пуст.
+func (g *Game) saveLocation(loc *worldpkg.Location) *worldpkg.Location {
+	locCopy := *loc
+	locCopy.Objects = map[int]*worldpkg.WorldObject{}
+
+	locState := g.State.LocationStates[loc.ID]
+	if locState == nil {
+		return &locCopy
+	}
+
+	locCopy.Foreground = append(worldpkg.IntSlice(nil), locState.Foreground...)
+	locCopy.Road = append(worldpkg.IntSlice(nil), locState.Road...)
+	locCopy.Ground = append(worldpkg.IntSlice(nil), locState.Ground...)
+	locCopy.Background = append(worldpkg.IntSlice(nil), locState.Background...)
+
+	// Существа в слое - только отображение, при загрузке они расставляются заново
+	for pos, val := range locCopy.Foreground {
+		if val < 0 {
+			locCopy.Foreground[pos] = g.foregroundObjectAt(loc.ID, pos)
+		}
+	}
+
+	return &locCopy
+}
+
+// writeSave записывает сохранение на диск с ротацией резервных копий
+func (g *Game) writeSave(data []byte) (string, error) {
+	g.saveMu.Lock()
+	defer g.saveMu.Unlock()
+
+	saveFile := g.SaveFile()
+	if err := worldpkg.RotateBackups(saveFile, g.SaveBackups); err != nil {
+		return saveFile, fmt.Errorf("ротация резервных копий: %w", err)
+	}
+	if err := worldpkg.WriteFileAtomic(saveFile, data, 0644); err != nil {
+		return saveFile, err
+	}
+	return saveFile, nil
+}
+
+// Save сохраняет мир. Снимок строится в игровом цикле, запись на диск идет
+// в вызывающей горутине. Если цикл не запускался или уже остановлен, снимок строится напрямую.
+// Нельзя вызывать из игрового цикла (там используется MarshalSave и writeSave).
+func (g *Game) Save() (string, error) {
+	var data []byte
+	var err error
+	snapshot := func(g *Game) {
+		data, err = g.MarshalSave()
+	}
+
+	if !g.started.Load() || !g.Do(snapshot) {
+		// Цикл не запускался или остановлен - других писателей нет, а Do ждал бы вечно
+		snapshot(g)
+	}
+	if err != nil {
+		return g.SaveFile(), err
+	}
+
+	return g.writeSave(data)
+}
+
+// Persistence - служба автосохранения мира: сохраняет его с заданным
+// интервалом и последний раз при остановке
+type Persistence struct {
+	Game     *Game
+	Interval time.Duration // Интервал автосохранения (0 - только при остановке)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewPersistence создает службу автосохранения
+func NewPersistence(g *Game, interval time.Duration) *Persistence {
+	return &Persistence{
+		Game:     g,
+		Interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start запускает автосохранение в отдельной горутине
+func (p *Persistence) Start() {
+	go p.run()
+}
+
+func (p *Persistence) run() {
+	defer close(p.done)
+
+	if p.Interval <= 0 {
+		<-p.stop
+		return
+	}
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.save("Автосохранение")
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Stop останавливает автосохранение и сохраняет мир последний раз
+func (p *Persistence) Stop() error {
+	var err error
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		<-p.done
+		err = p.save("Финальное сохранение")
+	})
+	return err
+}
+
+func (p *Persistence) save(reason string) error {
+	saveFile, err := p.Game.Save()
+	if err != nil {
+		fmt.Printf("%s: ошибка записи %s: %v\n", reason, saveFile, err)
+		return err
+	}
+	fmt.Printf("%s: мир сохранен в %s\n", reason, saveFile)
+	return nil
+}
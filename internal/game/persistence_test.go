@@ -0,0 +1,37 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSaveWithoutGameLoop(t *testing.T) {
+	g := NewGame(&worldpkg.World{Configs: testConfigs(t)})
+	g.SaveDir = t.TempDir()
+	g.Initialize()
+
+	// Игровой цикл не запускался (например, сервер не смог стартовать) - сохранение не должно его ждать
+	type result struct {
+		file string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		file, err := g.Save()
+		done <- result{file, err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("Save: %v", res.err)
+		}
+		if _, err := os.Stat(res.file); err != nil {
+			t.Errorf("файл сохранения не создан: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Save ждет игровой цикл, который не запускался")
+	}
+}
@@ -0,0 +1,169 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"errors"
+	"fmt"
+)
+
+// Ошибки реестра игроков
+var (
+	ErrAlreadyJoined     = errors.New("игрок уже присоединился")
+	ErrNotJoined         = errors.New("игрок не присоединился к игре")
+	ErrCharacterNotFound = errors.New("персонаж не найден")
+	ErrCharacterTaken    = errors.New("персонаж управляется другим игроком")
+	ErrCharacterNotOwned = errors.New("персонаж принадлежит другому игроку")
+	ErrLocationNotFound  = errors.New("локация не найдена")
+)
+
+// PlayerRegistry - реестр подключенных игроков (ID игрока -> персонаж)
+type PlayerRegistry struct {
+	players map[int]*worldpkg.Character
+}
+
+// NewPlayerRegistry создает пустой реестр игроков
+func NewPlayerRegistry() *PlayerRegistry {
+	return &PlayerRegistry{
+		players: make(map[int]*worldpkg.Character),
+	}
+}
+
+// Get возвращает персонажа игрока
+func (r *PlayerRegistry) Get(playerID int) *worldpkg.Character {
+	return r.players[playerID]
+}
+
+// Count возвращает количество подключенных игроков
+func (r *PlayerRegistry) Count() int {
+	return len(r.players)
+}
+
+// JoinPlayer присоединяет игрока к игре.
+// Если characterID == 0, для игрока создается новый персонаж в локации locationID,
+// иначе игрок занимает существующего свободного персонажа. Чужих персонажей занять нельзя,
+// а персонаж без владельца закрепляется за первым занявшим его игроком.
+func (g *Game) JoinPlayer(playerID, characterID, locationID int) (*worldpkg.Character, error) {
+	if _, ok := g.Players.players[playerID]; ok {
+		return nil, ErrAlreadyJoined
+	}
+
+	var char *worldpkg.Character
+	if characterID == 0 {
+		if g.GetLocation(locationID) == nil {
+			return nil, ErrLocationNotFound
+		}
+		char = g.CreateCharacter(fmt.Sprintf("Игрок %d", playerID), locationID)
+	} else {
+		char = g.GetCharacterByID(characterID)
+		if char == nil {
+			return nil, ErrCharacterNotFound
+		}
+		if char.Owner != 0 && char.Owner != playerID {
+			return nil, ErrCharacterNotOwned
+		}
+		if char.Controlled != 0 && char.Controlled != playerID {
+			return nil, ErrCharacterTaken
+		}
+	}
+
+	char.Owner = playerID
+	char.Controlled = playerID
+	g.Players.players[playerID] = char
+
+	fmt.Printf("Игрок %d управляет персонажем %s (ID: %d)\n", playerID, char.Name, char.ID)
+	return char, nil
+}
+
+// LeavePlayer освобождает персонажа отключившегося игрока
+func (g *Game) LeavePlayer(playerID int) {
+	char, ok := g.Players.players[playerID]
+	if !ok {
+		return
+	}
+
+	delete(g.Players.players, playerID)
+
+	g.CancelAction(char, CancelReasonLeft)
+	char.Controlled = 0
+	char.Direction = 0
+	char.Vertical = 0
+
+	fmt.Printf("Игрок %d покинул игру, персонаж %s свободен\n", playerID, char.Name)
+}
+
+// SuspendPlayer останавливает персонажа игрока, потерявшего соединение.
+// Персонаж остается за игроком, пока тот не возобновит сессию или не будет вызван LeavePlayer.
+func (g *Game) SuspendPlayer(playerID int) {
+	char, ok := g.Players.players[playerID]
+	if !ok {
+		return
+	}
+
+	char.Direction = 0
+	char.Vertical = 0
+
+	fmt.Printf("Игрок %d потерял соединение, персонаж %s остановлен\n", playerID, char.Name)
+}
+
+// GetCharacterForPlayer возвращает персонажа, которым управляет игрок
+func (g *Game) GetCharacterForPlayer(playerID int) *worldpkg.Character {
+	return g.Players.Get(playerID)
+}
+
+// GetCharacterByID возвращает персонажа по ID
+func (g *Game) GetCharacterByID(id int) *worldpkg.Character {
+	for _, char := range g.GameWorld.Characters {
+		if char.ID == id {
+			return char
+		}
+	}
+	return nil
+}
+
+// CreateCharacter создает нового персонажа в локации
+func (g *Game) CreateCharacter(name string, locationID int) *worldpkg.Character {
+	nextID := 1
+	for _, char := range g.GameWorld.Characters {
+		if char.ID >= nextID {
+			nextID = char.ID + 1
+		}
+	}
+
+	char := &worldpkg.Character{
+		ID:        nextID,
+		Name:      name,
+		Location:  locationID,
+		X:         float64(g.findSpawnPosition(locationID)),
+		Speed:     0.7,
+		Health:    DefaultCharacterHealth,
+		MaxHealth: DefaultCharacterHealth,
+		Stamina:   MaxVital,
+		Inventory: make(map[int]worldpkg.InventoryItem),
+		Equipped:  make(map[string]int),
+		HandsFree: true,
+	}
+
+	g.GameWorld.Characters = append(g.GameWorld.Characters, char)
+	g.State.CharsByLocation[locationID] = append(g.State.CharsByLocation[locationID], char)
+
+	return char
+}
+
+// findSpawnPosition ищет проходимую клетку ближе всего к центру локации
+func (g *Game) findSpawnPosition(locationID int) int {
+	locState := g.State.LocationStates[locationID]
+	if locState == nil || len(locState.Road) == 0 {
+		return 0
+	}
+
+	center := len(locState.Road) / 2
+	for offset := 0; offset <= center; offset++ {
+		for _, pos := range []int{center - offset, center + offset} {
+			if g.IsPositionWalkable(locationID, pos) {
+				return pos
+			}
+		}
+	}
+
+	return center
+}
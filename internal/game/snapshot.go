@@ -0,0 +1,108 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"maps"
+	"time"
+)
+
+// LocationSnapshot - неизменяемая копия состояния локации на момент тика
+type LocationSnapshot struct {
+	ID         int
+	Name       string
+	Foreground []int
+	Road       []int
+	Ground     []int
+	Background []int
+	Characters []*worldpkg.Character
+	Creatures  []*worldpkg.Creature
+	Objects    []*worldpkg.WorldObject
+}
+
+// Snapshot - снимок мира, построенный игровым циклом один раз за тик.
+// Снимок не изменяется после публикации, поэтому его можно читать из любых горутин.
+type Snapshot struct {
+	Time       time.Time
+	Tick       uint64 // Номер тика, после которого построен снимок
+	Locations  map[int]*LocationSnapshot
+	Characters map[int]*worldpkg.Character
+}
+
+// Snapshot возвращает последний опубликованный снимок мира
+func (g *Game) Snapshot() *Snapshot {
+	return g.snapshot.Load()
+}
+
+// publishSnapshot строит снимок текущего состояния и публикует его для читателей.
+// Вызывается только из игрового цикла.
+func (g *Game) publishSnapshot() {
+	snap := &Snapshot{
+		Time:       g.Clock.Now(),
+		Tick:       g.GameWorld.Tick,
+		Locations:  make(map[int]*LocationSnapshot, len(g.GameWorld.Locations)),
+		Characters: make(map[int]*worldpkg.Character, len(g.GameWorld.Characters)),
+	}
+
+	for _, loc := range g.GameWorld.Locations {
+		locState := g.State.LocationStates[loc.ID]
+		if locState == nil {
+			continue
+		}
+
+		locSnap := &LocationSnapshot{
+			ID:         loc.ID,
+			Name:       loc.Name,
+			Foreground: append([]int(nil), locState.Foreground...),
+			Road:       append([]int(nil), locState.Road...),
+			Ground:     append([]int(nil), locState.Ground...),
+			Background: append([]int(nil), locState.Background...),
+		}
+
+		for _, char := range g.State.CharsByLocation[loc.ID] {
+			charCopy := copyCharacter(char)
+			locSnap.Characters = append(locSnap.Characters, charCopy)
+			snap.Characters[charCopy.ID] = charCopy
+		}
+		for _, creature := range g.State.CreaturesByLocation[loc.ID] {
+			locSnap.Creatures = append(locSnap.Creatures, copyCreature(creature))
+		}
+		for _, obj := range g.State.ObjectsByLocation[loc.ID] {
+			locSnap.Objects = append(locSnap.Objects, copyObject(obj))
+		}
+
+		snap.Locations[loc.ID] = locSnap
+	}
+
+	g.snapshot.Store(snap)
+}
+
+// copyCharacter создает независимую копию персонажа
+func copyCharacter(char *worldpkg.Character) *worldpkg.Character {
+	c := *char
+	c.Inventory = maps.Clone(char.Inventory)
+	c.Equipped = maps.Clone(char.Equipped)
+	if char.Action != nil {
+		action := *char.Action
+		c.Action = &action
+	}
+	return &c
+}
+
+// copyCreature создает независимую копию существа
+func copyCreature(creature *worldpkg.Creature) *worldpkg.Creature {
+	c := *creature
+	if creature.CurrentBehavior != nil {
+		behavior := *creature.CurrentBehavior
+		c.CurrentBehavior = &behavior
+	}
+	c.Inventory = maps.Clone(creature.Inventory)
+	return &c
+}
+
+// copyObject создает независимую копию объекта
+func copyObject(obj *worldpkg.WorldObject) *worldpkg.WorldObject {
+	o := *obj
+	o.Storage = maps.Clone(obj.Storage)
+	o.CustomData = maps.Clone(obj.CustomData)
+	return &o
+}
@@ -2,6 +2,14 @@ package game
 
 import "LOIL-server/internal/world"
 
+// Псевдонимы типов мира, используемые сетевым мостом
+type (
+	Character     = world.Character
+	Creature      = world.Creature
+	WorldObject   = world.WorldObject
+	InventoryItem = world.InventoryItem
+)
+
 // LocationState - состояние локации в игре
 type LocationState struct {
 	Foreground []int // Положительные значения: объекты, персонажи; Отрицательные: существа
@@ -28,3 +36,44 @@ type CreatureBehaviorInfo struct {
 	TargetPos    int
 	Progress     float64 // Прогресс выполнения поведения (0-1)
 }
+
+// InteractionFailure - причина неудачи взаимодействия
+type InteractionFailure string
+
+const (
+	FailureNone               InteractionFailure = ""
+	FailureObjectNotFound     InteractionFailure = "object_not_found"
+	FailureOutOfReach         InteractionFailure = "out_of_reach"
+	FailureToolRequired       InteractionFailure = "tool_required"
+	FailureInvalidInteraction InteractionFailure = "invalid_interaction"
+	FailureCreatureNotFound   InteractionFailure = "creature_not_found"
+	FailureCooldown           InteractionFailure = "cooldown"
+)
+
+// InteractionOutcome - фактический результат взаимодействия с объектом
+type InteractionOutcome struct {
+	Success       bool
+	Failure       InteractionFailure
+	Message       string
+	ObjectID      int
+	Interaction   string                // Тип взаимодействия (pick, chop, ...)
+	Items         []world.InventoryItem // Реально добавленные в инвентарь предметы
+	Object        *world.WorldObject    // Объект после взаимодействия (nil при неудаче)
+	TransformedTo int                   // Новый тип объекта, если он превратился
+	Destroyed     bool                  // Объект уничтожен
+
+	// Результат атаки существа
+	CreatureID  int
+	Creature    *world.Creature // Существо после удара (nil при неудаче)
+	Damage      int
+	Killed      bool
+	ContainerID int // Хранилище с добычей погибшего существа
+}
+
+// fail помечает результат как неудачный
+func (o *InteractionOutcome) fail(failure InteractionFailure, message string) *InteractionOutcome {
+	o.Success = false
+	o.Failure = failure
+	o.Message = message
+	return o
+}
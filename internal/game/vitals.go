@@ -0,0 +1,231 @@
+package game
+
+import (
+	worldpkg "LOIL-server/internal/world"
+	"errors"
+	"fmt"
+)
+
+// Скорость изменения показателей персонажа (в единицах за секунду)
+const (
+	MaxVital = 100.0 // Верхняя граница голода, жажды и выносливости
+
+	hungerRate        = MaxVital / 1800 // Голод от 0 до 100 за 30 минут
+	thirstRate        = MaxVital / 1200 // Жажда от 0 до 100 за 20 минут
+	movingNeedsFactor = 1.5             // Во время ходьбы голод и жажда растут быстрее
+	staminaDrain      = 10.0            // Расход выносливости при ходьбе
+	staminaRegen      = 15.0            // Восстановление выносливости на месте
+	exhaustedSpeed    = 0.5             // Множитель скорости без выносливости
+	drinkAmount       = 40.0            // Сколько жажды утоляет один глоток
+	defaultNutrition  = 10              // Сытость еды без nutrition в конфиге
+
+	starvationDamage = 1  // Урон в секунду от голода или жажды на максимуме
+	healthRegen      = 1  // Восстановление здоровья в секунду у сытого персонажа
+	wellFedThreshold = 50 // Голод и жажда ниже порога - здоровье восстанавливается
+)
+
+// Причины урона, не связанные с боем
+const (
+	DamageHunger = "hunger"
+	DamageThirst = "thirst"
+)
+
+// Ошибки еды и питья
+var (
+	ErrNotFood     = errors.New("предмет нельзя съесть")
+	ErrNoWaterNear = errors.New("рядом нет воды")
+)
+
+// SpawnPoint - место появления персонажей после гибели.
+// X < 0 - ближайшая к центру локации проходимая клетка.
+type SpawnPoint struct {
+	LocationID int
+	X          int
+}
+
+// CharacterDeathEvent - данные события character_died
+type CharacterDeathEvent struct {
+	CharacterID int
+	Name        string
+	X           float64
+	Cause       string // Кто или что убило персонажа (character, creature, hunger, thirst)
+	KillerID    int
+	ContainerID int // Хранилище с выпавшими предметами (0 - инвентарь был пуст)
+}
+
+// UpdateVitals изменяет голод, жажду и выносливость персонажа за elapsed секунд.
+// Раз в секунду (каждые TickRate тиков) голод и жажда на максимуме отнимают здоровье,
+// а сытость восстанавливает его. Возвращает true, если изменились целые значения показателей.
+func (g *Game) UpdateVitals(char *worldpkg.Character, elapsed float64) bool {
+	before := vitalsKey(char)
+
+	needs := elapsed
+	if char.Direction != 0 {
+		needs *= movingNeedsFactor
+		char.Stamina = max(0, char.Stamina-staminaDrain*elapsed)
+	} else {
+		char.Stamina = min(MaxVital, char.Stamina+staminaRegen*elapsed)
+	}
+	char.Hunger = min(MaxVital, char.Hunger+hungerRate*needs)
+	char.Thirst = min(MaxVital, char.Thirst+thirstRate*needs)
+
+	if g.GameWorld.Tick%TickRate == 0 {
+		switch {
+		case char.Hunger >= MaxVital:
+			g.DamageCharacter(char, starvationDamage, DamageHunger, 0)
+		case char.Thirst >= MaxVital:
+			g.DamageCharacter(char, starvationDamage, DamageThirst, 0)
+		case char.Hunger < wellFedThreshold && char.Thirst < wellFedThreshold:
+			char.Health = min(char.MaxHealth, char.Health+healthRegen)
+		}
+	}
+
+	return vitalsKey(char) != before
+}
+
+// vitalsKey - целые значения показателей (то, что видит клиент)
+func vitalsKey(char *worldpkg.Character) [4]int {
+	return [4]int{char.Health, int(char.Hunger), int(char.Thirst), int(char.Stamina)}
+}
+
+// movementFactor возвращает множитель скорости персонажа с учетом усталости
+func movementFactor(char *worldpkg.Character) float64 {
+	if char.Stamina <= 0 {
+		return exhaustedSpeed
+	}
+	return 1
+}
+
+// Eat съедает один предмет еды из слота инвентаря
+func (g *Game) Eat(char *worldpkg.Character, slot int) error {
+	item, ok := char.Inventory[slot]
+	if !ok || item.Count <= 0 {
+		return ErrEmptySlot
+	}
+	itemConfig := g.GetItemConfig(item.ItemID)
+	if itemConfig == nil || itemConfig.Type != "food" {
+		return ErrNotFood
+	}
+
+	nutrition := itemConfig.Nutrition
+	if nutrition <= 0 {
+		nutrition = defaultNutrition
+	}
+
+	g.takeFromSlot(char, slot, 1)
+	char.Hunger = max(0, char.Hunger-float64(nutrition))
+
+	fmt.Printf("%s съел %s, голод %.0f\n", char.Name, itemConfig.Name, char.Hunger)
+	g.notifyUpdate()
+	return nil
+}
+
+// Drink утоляет жажду, если персонаж стоит на воде или рядом с ней
+func (g *Game) Drink(char *worldpkg.Character) error {
+	if !g.nearWater(char) {
+		return ErrNoWaterNear
+	}
+
+	char.Thirst = max(0, char.Thirst-drinkAmount)
+
+	fmt.Printf("%s пьет воду, жажда %.0f\n", char.Name, char.Thirst)
+	g.notifyUpdate()
+	return nil
+}
+
+// nearWater проверяет, есть ли питьевая вода на клетке персонажа или на соседних
+func (g *Game) nearWater(char *worldpkg.Character) bool {
+	locState := g.State.LocationStates[char.Location]
+	if locState == nil {
+		return false
+	}
+
+	pos := int(char.X + 0.5)
+	for _, cell := range []int{pos, pos - 1, pos + 1} {
+		if cell < 0 || cell >= len(locState.Ground) {
+			continue
+		}
+		if ground := g.GetGroundConfig(locState.Ground[cell]); ground != nil && ground.Drinkable {
+			return true
+		}
+	}
+	return false
+}
+
+// KillCharacter обрабатывает гибель персонажа: инвентарь и снаряжение выпадают
+// в хранилище на месте гибели, а персонаж появляется в точке возрождения.
+func (g *Game) KillCharacter(char *worldpkg.Character, cause string, killerID int) {
+	fmt.Printf("%s погибает\n", char.Name)
+
+	g.CancelAction(char, CancelReasonDied)
+
+	// Все предметы персонажа выпадают в хранилище
+	var items []worldpkg.InventoryItem
+	for _, slot := range sortedSlots(char.Inventory) {
+		items = append(items, char.Inventory[slot])
+	}
+	for _, itemID := range char.Equipped {
+		items = append(items, worldpkg.InventoryItem{ItemID: itemID, Count: 1})
+	}
+	char.Inventory = make(map[int]worldpkg.InventoryItem)
+	char.Equipped = make(map[string]int)
+	char.HandsFree = true
+	g.inventoryChanged(char)
+
+	event := &CharacterDeathEvent{
+		CharacterID: char.ID,
+		Name:        char.Name,
+		X:           char.X,
+		Cause:       cause,
+		KillerID:    killerID,
+	}
+	locationID := char.Location
+	if container := g.DropContainer(char.Location, char.X, items); container != nil {
+		event.ContainerID = container.ID
+	}
+
+	g.emit(GameEvent{
+		Type:        EventCharacterDied,
+		CharacterID: char.ID,
+		LocationID:  locationID,
+		Payload:     event,
+	})
+
+	g.RespawnCharacter(char)
+}
+
+// RespawnCharacter возвращает персонажа в точку возрождения с полными показателями
+func (g *Game) RespawnCharacter(char *worldpkg.Character) {
+	locationID := g.Spawn.LocationID
+	if g.State.LocationStates[locationID] == nil {
+		// Точка возрождения не задана или указывает на несуществующую локацию
+		locationID = char.Location
+	}
+
+	x := g.Spawn.X
+	if x < 0 || x >= len(g.State.LocationStates[locationID].Road) {
+		x = g.findSpawnPosition(locationID)
+	}
+
+	g.moveCharacter(char, locationID, float64(x))
+
+	char.Direction = 0
+	char.Vertical = 0
+	char.Health = char.MaxHealth
+	char.Hunger = 0
+	char.Thirst = 0
+	char.Stamina = MaxVital
+
+	fmt.Printf("%s возрождается в локации %d на клетке %d\n", char.Name, locationID, x)
+	g.notifyUpdate()
+}
+
+// moveCharacter переносит персонажа в точку x локации locationID
+func (g *Game) moveCharacter(char *worldpkg.Character, locationID int, x float64) {
+	if char.Location != locationID {
+		g.State.CharsByLocation[char.Location] = g.removeCharFromSlice(g.State.CharsByLocation[char.Location], char)
+		char.Location = locationID
+		g.State.CharsByLocation[locationID] = append(g.State.CharsByLocation[locationID], char)
+	}
+	char.X = x
+}
@@ -0,0 +1,286 @@
+// Package metrics - метрики сервера в текстовом формате Prometheus (только stdlib)
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Collector - метрика, которую реестр выводит в текстовом формате
+type Collector interface {
+	// WriteText пишет описание (# HELP, # TYPE) и значения метрики
+	WriteText(w io.Writer)
+}
+
+// Registry - набор метрик, отдаваемых по /metrics
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry создает пустой реестр
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register добавляет метрики в реестр
+func (r *Registry) Register(collectors ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, collectors...)
+}
+
+// WriteText выводит все метрики реестра в порядке регистрации
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, collector := range collectors {
+		collector.WriteText(buf)
+	}
+	return buf.Flush()
+}
+
+// ServeHTTP отдает метрики в формате text/plain version 0.0.4
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteText(w)
+}
+
+// Counter - монотонно растущий счетчик
+type Counter struct {
+	value int64
+}
+
+// Inc увеличивает счетчик на 1
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.value, 1)
+}
+
+// Add увеличивает счетчик на n (n >= 0)
+func (c *Counter) Add(n int64) {
+	atomic.AddInt64(&c.value, n)
+}
+
+// Value возвращает текущее значение
+func (c *Counter) Value() int64 {
+	return atomic.LoadInt64(&c.value)
+}
+
+// CounterVec - счетчики с метками
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu       sync.RWMutex
+	counters map[string]*labeledCounter
+}
+
+type labeledCounter struct {
+	values []string
+	Counter
+}
+
+// NewCounterVec создает счетчик с метками labels. Без меток счетчик один.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		name:     name,
+		help:     help,
+		labels:   labels,
+		counters: make(map[string]*labeledCounter),
+	}
+}
+
+// With возвращает счетчик для значений меток (в порядке labels)
+func (v *CounterVec) With(values ...string) *Counter {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s ожидает %d меток, передано %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	counter, ok := v.counters[key]
+	v.mu.RUnlock()
+	if ok {
+		return &counter.Counter
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if counter, ok = v.counters[key]; !ok {
+		counter = &labeledCounter{values: append([]string(nil), values...)}
+		v.counters[key] = counter
+	}
+	return &counter.Counter
+}
+
+func (v *CounterVec) WriteText(w io.Writer) {
+	writeHeader(w, v.name, v.help, "counter")
+
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.counters))
+	for key := range v.counters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		counter := v.counters[key]
+		writeSample(w, v.name, formatLabels(v.labels, counter.values, "", ""), float64(counter.Value()))
+	}
+	v.mu.RUnlock()
+}
+
+// Histogram - распределение значений по корзинам
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64 // Верхние границы корзин по возрастанию
+
+	mu     sync.Mutex
+	counts []uint64 // Количество значений в каждой корзине (не накопленное)
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram создает гистограмму с верхними границами корзин buckets
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// Observe добавляет значение в гистограмму
+func (h *Histogram) Observe(value float64) {
+	idx := sort.SearchFloat64s(h.buckets, value)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if idx < len(h.counts) {
+		h.counts[idx]++
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *Histogram) WriteText(w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		writeSample(w, h.name+"_bucket", formatLabels(nil, nil, "le", formatFloat(bound)), float64(cumulative))
+	}
+	writeSample(w, h.name+"_bucket", formatLabels(nil, nil, "le", "+Inf"), float64(h.count))
+	writeSample(w, h.name+"_sum", "", h.sum)
+	writeSample(w, h.name+"_count", "", float64(h.count))
+}
+
+// GaugeFunc - значения, вычисляемые в момент запроса метрик
+type GaugeFunc struct {
+	name    string
+	help    string
+	labels  []string
+	collect func(emit func(value float64, labelValues ...string))
+}
+
+// NewGaugeFunc создает метрику, значения которой выдает collect через emit
+func NewGaugeFunc(name, help string, labels []string, collect func(emit func(value float64, labelValues ...string))) *GaugeFunc {
+	return &GaugeFunc{name: name, help: help, labels: labels, collect: collect}
+}
+
+func (g *GaugeFunc) WriteText(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	g.collect(func(value float64, labelValues ...string) {
+		writeSample(w, g.name, formatLabels(g.labels, labelValues, "", ""), value)
+	})
+}
+
+// ExponentialBuckets возвращает count границ корзин, начиная со start с множителем factor
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, kind)
+}
+
+func writeSample(w io.Writer, name, labels string, value float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(value))
+}
+
+// formatLabels собирает {name="value",...}; extraName/extraValue добавляются в конец (для le)
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, escapeLabel(value))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extraName, escapeLabel(extraValue))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
@@ -0,0 +1,232 @@
+package network
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	passwordIterations = 100000
+	passwordKeyLength  = 32
+	minUsernameLength  = 3
+	maxUsernameLength  = 32
+	minPasswordLength  = 6
+)
+
+// Account - учетная запись игрока
+type Account struct {
+	Username     string    `json:"username"`
+	PlayerID     int       `json:"player_id"`
+	Salt         []byte    `json:"salt"`
+	PasswordHash []byte    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// accountsFile - формат файла учетных записей
+type accountsFile struct {
+	NextPlayerID int                 `json:"next_player_id"`
+	Accounts     map[string]*Account `json:"accounts"`
+}
+
+// AccountStore - хранилище учетных записей в JSON файле
+type AccountStore struct {
+	filename string
+	data     accountsFile
+	mu       sync.Mutex
+}
+
+// LoadAccountStore загружает учетные записи из файла (файл создается при первой регистрации)
+func LoadAccountStore(filename string) (*AccountStore, error) {
+	store := &AccountStore{
+		filename: filename,
+		data: accountsFile{
+			NextPlayerID: 1,
+			Accounts:     make(map[string]*Account),
+		},
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &store.data); err != nil {
+		return nil, err
+	}
+	if store.data.Accounts == nil {
+		store.data.Accounts = make(map[string]*Account)
+	}
+
+	return store, nil
+}
+
+// Register создает новую учетную запись
+func (s *AccountStore) Register(username, password string) (*Account, error) {
+	if n := utf8.RuneCountInString(username); n < minUsernameLength || n > maxUsernameLength {
+		return nil, NewError("invalid_username", "Имя пользователя должно быть от 3 до 32 символов")
+	}
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return nil, NewError("weak_password", "Пароль должен быть не короче 6 символов")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.data.Accounts[username]; exists {
+		return nil, NewError("username_taken", "Имя пользователя уже занято")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	hash, err := hashPassword(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Username:     username,
+		PlayerID:     s.data.NextPlayerID,
+		Salt:         salt,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}
+
+	s.data.Accounts[username] = account
+	s.data.NextPlayerID++
+
+	if err := s.save(); err != nil {
+		delete(s.data.Accounts, username)
+		s.data.NextPlayerID--
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Login проверяет имя пользователя и пароль
+func (s *AccountStore) Login(username, password string) (*Account, error) {
+	s.mu.Lock()
+	account, ok := s.data.Accounts[username]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, NewError("invalid_credentials", "Неверное имя пользователя или пароль")
+	}
+
+	hash, err := hashPassword(password, account.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(hash, account.PasswordHash) != 1 {
+		return nil, NewError("invalid_credentials", "Неверное имя пользователя или пароль")
+	}
+
+	return account, nil
+}
+
+// save записывает учетные записи во временный файл и переименовывает его
+func (s *AccountStore) save() error {
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.filename), 0755); err != nil {
+		return err
+	}
+
+	tmpFile := s.filename + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, s.filename)
+}
+
+// hashPassword вычисляет хеш пароля с солью
+func hashPassword(password string, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLength)
+}
+
+// Credentials - запрос на регистрацию или вход
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AuthResponse - ответ на регистрацию или вход
+type AuthResponse struct {
+	PlayerID int    `json:"player_id"`
+	Token    string `json:"token"`
+}
+
+// handleRegister - HTTP обработчик регистрации
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	s.handleCredentials(w, r, s.Accounts.Register)
+}
+
+// handleLogin - HTTP обработчик входа
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	s.handleCredentials(w, r, s.Accounts.Login)
+}
+
+// handleCredentials проверяет учетные данные и выдает токен
+func (s *Server) handleCredentials(w http.ResponseWriter, r *http.Request, check func(username, password string) (*Account, error)) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorMessage{Code: "method_not_allowed", Message: "Используйте POST"})
+		return
+	}
+
+	var creds Credentials
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&creds); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorMessage{Code: "invalid_request", Message: "Неверный формат запроса"})
+		return
+	}
+
+	account, err := check(creds.Username, creds.Password)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch GetErrorCode(err) {
+		case "invalid_credentials":
+			status = http.StatusUnauthorized
+		case "username_taken":
+			status = http.StatusConflict
+		case "invalid_username", "weak_password":
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, ErrorMessage{Code: GetErrorCode(err), Message: err.Error()})
+		return
+	}
+
+	token, err := s.Auth.IssueToken(account.PlayerID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorMessage{Code: GetErrorCode(err), Message: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AuthResponse{
+		PlayerID: account.PlayerID,
+		Token:    token,
+	})
+}
+
+// writeJSON отправляет JSON ответ
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
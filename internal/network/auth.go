@@ -0,0 +1,95 @@
+package network
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Authenticator - проверка и выдача токенов доступа игроков
+type Authenticator interface {
+	// Authenticate проверяет токен и возвращает ID игрока
+	Authenticate(token string) (int, error)
+	// IssueToken выдает новый токен для игрока
+	IssueToken(playerID int) (string, error)
+}
+
+// HMACAuthenticator - токены вида "<player_id>.<expires_unix>.<подпись>",
+// подписанные HMAC-SHA256 секретом сервера
+type HMACAuthenticator struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewHMACAuthenticator создает аутентификатор с секретом и временем жизни токена
+func NewHMACAuthenticator(secret []byte, ttl time.Duration) *HMACAuthenticator {
+	return &HMACAuthenticator{
+		secret: secret,
+		ttl:    ttl,
+	}
+}
+
+// GenerateSecret создает случайный секрет для подписи токенов
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// IssueToken выдает подписанный токен для игрока
+func (a *HMACAuthenticator) IssueToken(playerID int) (string, error) {
+	if playerID <= 0 {
+		return "", NewError("invalid_player", "Неверный ID игрока")
+	}
+
+	expires := time.Now().Add(a.ttl).Unix()
+	payload := fmt.Sprintf("%d.%d", playerID, expires)
+
+	return payload + "." + a.sign(payload), nil
+}
+
+// Authenticate проверяет подпись и срок действия токена
+func (a *HMACAuthenticator) Authenticate(token string) (int, error) {
+	if token == "" {
+		return 0, NewError("auth_required", "Требуется токен авторизации")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, NewError("invalid_token", "Неверный формат токена")
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(a.sign(payload))) {
+		return 0, NewError("invalid_token", "Неверная подпись токена")
+	}
+
+	playerID, err := strconv.Atoi(parts[0])
+	if err != nil || playerID <= 0 {
+		return 0, NewError("invalid_token", "Неверный ID игрока в токене")
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, NewError("invalid_token", "Неверный срок действия токена")
+	}
+	if time.Now().Unix() > expires {
+		return 0, NewError("token_expired", "Срок действия токена истек")
+	}
+
+	return playerID, nil
+}
+
+// sign вычисляет подпись полезной нагрузки токена
+func (a *HMACAuthenticator) sign(payload string) string {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,269 @@
+package network
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// emotes - доступные эмоции и их описание (после имени персонажа)
+var emotes = map[string]string{
+	"wave":  "машет рукой",
+	"bow":   "кланяется",
+	"laugh": "смеется",
+	"cheer": "радуется",
+	"dance": "танцует",
+	"sit":   "садится отдохнуть",
+}
+
+// ChatFilter - проверка сообщения чата перед отправкой. Фильтр может изменить
+// текст сообщения или отклонить его, вернув ошибку (код ошибки получит отправитель).
+type ChatFilter func(msg *ChatMessage) error
+
+// ProfanityFilter заменяет звездочками слова из списка (без учета регистра)
+func ProfanityFilter(words []string) ChatFilter {
+	banned := make(map[string]bool, len(words))
+	for _, word := range words {
+		banned[strings.ToLower(word)] = true
+	}
+
+	return func(msg *ChatMessage) error {
+		fields := strings.FieldsFunc(msg.Text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, field := range fields {
+			if banned[strings.ToLower(field)] {
+				msg.Text = strings.ReplaceAll(msg.Text, field, strings.Repeat("*", utf8.RuneCountInString(field)))
+			}
+		}
+		return nil
+	}
+}
+
+// DuplicateFilter отклоняет повтор того же текста от игрока чаще, чем раз в interval
+func DuplicateFilter(interval time.Duration) ChatFilter {
+	type lastMessage struct {
+		text string
+		at   time.Time
+	}
+
+	var mu sync.Mutex
+	last := make(map[int]lastMessage)
+
+	return func(msg *ChatMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		prev, ok := last[msg.FromPlayer]
+		if ok && prev.text == msg.Text && now.Sub(prev.at) < interval {
+			return NewError("chat_duplicate", "Не повторяйте одно и то же сообщение")
+		}
+		last[msg.FromPlayer] = lastMessage{text: msg.Text, at: now}
+		return nil
+	}
+}
+
+// chatHistory - последние сообщения чата по локациям
+type chatHistory struct {
+	mu        sync.Mutex
+	locations map[int][]*ChatMessage
+}
+
+// add добавляет сообщение в историю локации, сохраняя не больше limit сообщений
+func (h *chatHistory) add(locationID int, msg *ChatMessage, limit int) {
+	if limit <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.locations == nil {
+		h.locations = make(map[int][]*ChatMessage)
+	}
+	history := append(h.locations[locationID], msg)
+	if over := len(history) - limit; over > 0 {
+		history = append(history[:0], history[over:]...)
+	}
+	h.locations[locationID] = history
+}
+
+// get возвращает копию истории локации
+func (h *chatHistory) get(locationID int) []*ChatMessage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*ChatMessage(nil), h.locations[locationID]...)
+}
+
+// handleChat обрабатывает сообщение чата
+func (c *Client) handleChat(payload interface{}) {
+	if c.Info.PlayerID == 0 {
+		c.sendError("not_joined", "Сначала нужно присоединиться к игре")
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		c.sendError("parse_error", "Ошибка парсинга запроса")
+		return
+	}
+
+	var req ChatRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.sendError("invalid_request", "Неверный формат сообщения чата")
+		return
+	}
+
+	text, ok := c.cleanChatText(req.Text)
+	if !ok {
+		return
+	}
+
+	msg := c.newChatMessage(req.Scope, text)
+	switch req.Scope {
+	case ChatLocal, ChatGlobal:
+	case ChatWhisper:
+		if req.To == 0 || !c.Server.playerOnline(req.To) {
+			c.sendError("player_not_found", "Игрок не в сети")
+			return
+		}
+		msg.ToPlayer = req.To
+	default:
+		c.sendError("invalid_scope", "Область чата должна быть local, global или whisper")
+		return
+	}
+
+	c.Server.deliverChat(c, msg)
+}
+
+// handleEmote обрабатывает эмоцию персонажа
+func (c *Client) handleEmote(payload interface{}) {
+	if c.Info.PlayerID == 0 {
+		c.sendError("not_joined", "Сначала нужно присоединиться к игре")
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		c.sendError("parse_error", "Ошибка парсинга запроса")
+		return
+	}
+
+	var req EmoteRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.sendError("invalid_request", "Неверный формат эмоции")
+		return
+	}
+
+	action, ok := emotes[req.Emote]
+	if !ok {
+		c.sendError("unknown_emote", "Неизвестная эмоция: "+req.Emote)
+		return
+	}
+
+	msg := c.newChatMessage(ChatEmote, "")
+	msg.Emote = req.Emote
+	msg.Text = msg.FromName + " " + action
+	c.Server.deliverChat(c, msg)
+}
+
+// cleanChatText убирает управляющие символы и проверяет длину сообщения
+func (c *Client) cleanChatText(text string) (string, bool) {
+	if !utf8.ValidString(text) {
+		c.sendError("invalid_text", "Текст должен быть в UTF-8")
+		return "", false
+	}
+
+	text = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text))
+
+	if text == "" {
+		c.sendError("empty_message", "Пустое сообщение")
+		return "", false
+	}
+	if maxLength := c.Server.Config.ChatMaxLength; maxLength > 0 && utf8.RuneCountInString(text) > maxLength {
+		c.sendError("message_too_long", "Сообщение длиннее допустимого")
+		return "", false
+	}
+	return text, true
+}
+
+// newChatMessage создает сообщение от персонажа клиента
+func (c *Client) newChatMessage(scope, text string) *ChatMessage {
+	info := c.GetInfo()
+	msg := &ChatMessage{
+		Scope:         scope,
+		FromPlayer:    info.PlayerID,
+		FromCharacter: info.CharacterID,
+		Text:          text,
+		ServerTime:    Now(),
+	}
+
+	if char := c.Server.Game.GetCharacterByID(info.CharacterID); char != nil {
+		msg.FromName = char.Name
+		msg.LocationID = char.LocationID
+	}
+	return msg
+}
+
+// deliverChat пропускает сообщение через фильтры и доставляет получателям
+func (s *Server) deliverChat(from *Client, msg *ChatMessage) {
+	for _, filter := range s.ChatFilters {
+		if err := filter(msg); err != nil {
+			code := "chat_rejected"
+			if IsGameError(err) {
+				code = GetErrorCode(err)
+			}
+			from.sendError(code, err.Error())
+			return
+		}
+	}
+
+	switch msg.Scope {
+	case ChatWhisper:
+		// Получатель и отправитель (копия для истории отправителя)
+		msg.LocationID = 0
+		s.SendToPlayer(msg.ToPlayer, MsgChat, msg)
+		if msg.ToPlayer != msg.FromPlayer {
+			s.SendToPlayer(msg.FromPlayer, MsgChat, msg)
+		}
+
+	case ChatGlobal:
+		msg.LocationID = 0
+		s.sendToJoined(func(info ClientInfo) bool { return true }, MsgChat, msg)
+
+	default:
+		// local и emote видны в локации и попадают в ее историю
+		s.chat.add(msg.LocationID, msg, s.Config.ChatHistorySize)
+		s.SendToLocation(msg.LocationID, MsgChat, msg)
+	}
+
+	log.Printf("Чат [%s] игрок %d: %s", msg.Scope, msg.FromPlayer, msg.Text)
+}
+
+// playerOnline проверяет, подключен ли игрок (или ждет переподключения)
+func (s *Server) playerOnline(playerID int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, client := range s.Clients {
+		if client.GetInfo().PlayerID == playerID {
+			return true
+		}
+	}
+	for _, session := range s.sessions {
+		if session.PlayerID == playerID && session.detached() {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package network
 import (
 	"encoding/json"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,26 +15,36 @@ func (c *Client) writePump() {
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
-		c.Server.Unregister <- c
+		c.Server.unregister(c)
+		c.Server.pumps.Done()
 	}()
 
 	for {
 		select {
 		case message, ok := <-c.Send:
 			if !ok {
-				// Канал закрыт
-				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// Канал закрыт - отправляем close frame с кодом причины
+				c.sendMu.Lock()
+				code, text := c.closeCode, c.closeText
+				c.sendMu.Unlock()
+
+				c.mu.Lock()
+				c.Conn.SetWriteDeadline(time.Now().Add(c.Server.Config.WriteTimeout))
+				c.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, text))
+				c.mu.Unlock()
 				return
 			}
 
 			c.mu.Lock()
-			err := c.Conn.WriteMessage(websocket.TextMessage, message)
+			c.Conn.SetWriteDeadline(time.Now().Add(c.Server.Config.WriteTimeout))
+			err := c.Conn.WriteMessage(c.codec.FrameType(), message)
 			c.mu.Unlock()
 
 			if err != nil {
 				log.Printf("Ошибка отправки клиенту %s: %v", c.Info.ID, err)
 				return
 			}
+			c.Server.metrics.bytesSent.With().Add(int64(len(message)))
 
 		case <-ticker.C:
 			// Уже обрабатывается в sendPings сервера
@@ -44,8 +55,9 @@ func (c *Client) writePump() {
 // readPump читает сообщения от клиента
 func (c *Client) readPump() {
 	defer func() {
-		c.Server.Unregister <- c
+		c.Server.unregister(c)
 		c.Conn.Close()
+		c.Server.pumps.Done()
 	}()
 
 	c.Conn.SetReadLimit(c.Server.Config.MaxMessageSize)
@@ -54,29 +66,46 @@ func (c *Client) readPump() {
 	// Устанавливаем pong handler
 	c.Conn.SetPongHandler(func(string) error {
 		c.Conn.SetReadDeadline(time.Now().Add(c.Server.Config.ReadTimeout))
-		c.Info.LastActivity = time.Now()
+		c.touch()
 		return nil
 	})
 
 	for {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
+			// Клиент вышел сам - его персонажа не нужно держать для переподключения
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.leaving.Store(true)
+			}
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("Ошибка чтения от клиента %s: %v", c.Info.ID, err)
 			}
 			break
 		}
 
-		c.Info.LastActivity = time.Now()
+		c.touch()
 		c.handleMessage(message)
+
+		// Клиент отключен за флуд - остальные сообщения не читаем
+		if c.isClosed() {
+			break
+		}
 	}
 }
 
 // handleMessage обрабатывает входящее сообщение
 func (c *Client) handleMessage(data []byte) {
-	var msg Message
-	if err := json.Unmarshal(data, &msg); err != nil {
-		c.sendError("invalid_format", "Неверный формат JSON")
+	msg, err := c.codec.Decode(data)
+	if err != nil {
+		c.Server.metrics.received("")
+		if c.checkRate("") {
+			c.sendError("invalid_format", "Неверный формат сообщения")
+		}
+		return
+	}
+
+	c.Server.metrics.received(msg.Type)
+	if !c.checkRate(msg.Type) {
 		return
 	}
 
@@ -84,15 +113,39 @@ func (c *Client) handleMessage(data []byte) {
 	switch msg.Type {
 	case MsgJoin:
 		c.handleJoin(msg.Payload)
+	case MsgResume:
+		c.handleResume(msg.Payload)
 	case MsgMove:
 		c.handleMove(msg.Payload)
 	case MsgStop:
 		c.handleStop()
 	case MsgInteract:
 		c.handleInteract(msg.Payload)
+	case MsgAck:
+		c.handleAck(msg.Payload)
+	case MsgChat:
+		c.handleChat(msg.Payload)
+	case MsgEmote:
+		c.handleEmote(msg.Payload)
+	case MsgEat:
+		c.handleEat(msg.Payload)
+	case MsgDrink:
+		c.handleDrink()
+	case MsgAttack:
+		c.handleAttack(msg.Payload)
+	case MsgInventoryMove:
+		c.handleInventoryMove(msg.Payload)
+	case MsgInventorySplit:
+		c.handleInventorySplit(msg.Payload)
+	case MsgInventoryDrop:
+		c.handleInventoryDrop(msg.Payload)
+	case MsgEquip:
+		c.handleEquip(msg.Payload)
+	case MsgUnequip:
+		c.handleUnequip(msg.Payload)
 	case MsgPong:
 		// Обновляем время последней активности
-		c.Info.LastActivity = time.Now()
+		c.touch()
 	default:
 		c.sendError("unknown_type", "Неизвестный тип сообщения: "+string(msg.Type))
 	}
@@ -102,85 +155,164 @@ func (c *Client) handleMessage(data []byte) {
 func (c *Client) handleJoin(payload interface{}) {
 	data, err := json.Marshal(payload)
 	if err != nil {
-		c.sendError("parse_error", "Ошибка парсинга запроса")
+		c.joinFailed("parse_error", "Ошибка парсинга запроса")
 		return
 	}
 
 	var req JoinRequest
 	if err := json.Unmarshal(data, &req); err != nil {
-		c.sendError("invalid_request", "Неверный формат запроса")
+		c.joinFailed("invalid_request", "Неверный формат запроса")
 		return
 	}
 
+	// Проверяем токен авторизации
+	if c.Server.Auth != nil {
+		playerID, err := c.Server.Auth.Authenticate(req.Token)
+		if err != nil {
+			c.joinFailed(GetErrorCode(err), err.Error())
+			return
+		}
+		if req.PlayerID == 0 {
+			req.PlayerID = playerID
+		} else if req.PlayerID != playerID {
+			c.joinFailed("player_mismatch", "Токен выдан другому игроку")
+			return
+		}
+	}
+
 	// Проверяем обязательные поля
 	if req.PlayerID == 0 || req.LocationID == 0 {
-		c.sendError("missing_fields", "Не указаны player_id или location_id")
+		c.joinFailed("missing_fields", "Не указаны player_id или location_id")
 		return
 	}
 
-	// Сохраняем информацию о клиенте
-	c.Info.PlayerID = req.PlayerID
-	c.Info.CharacterID = req.CharacterID
-	c.Info.LocationID = req.LocationID
+	if c.Info.PlayerID != 0 {
+		c.joinFailed("already_joined", "Клиент уже присоединился к игре")
+		return
+	}
+
+	// Игрок, не дождавшийся возобновления, присоединяется заново - старая сессия завершается
+	c.Server.takeOverDetachedSession(req.PlayerID)
 
 	// Уведомляем игру о присоединении
 	charState, err := c.Server.Game.HandleJoin(req.PlayerID, req.CharacterID, req.LocationID)
 	if err != nil {
-		c.sendError("join_failed", err.Error())
+		code := "join_failed"
+		if IsGameError(err) {
+			code = GetErrorCode(err)
+		}
+		c.joinFailed(code, err.Error())
 		return
 	}
 
-	// Устанавливаем CharacterID из ответа игры (если не был указан)
-	if c.Info.CharacterID == 0 && charState != nil {
-		c.Info.CharacterID = charState.ID
+	// Сохраняем информацию о клиенте (локация берется из персонажа)
+	c.infoMu.Lock()
+	c.Info.PlayerID = req.PlayerID
+	c.Info.CharacterID = charState.ID
+	c.Info.LocationID = charState.LocationID
+	c.infoMu.Unlock()
+
+	// Выдаем сессию для переподключения
+	session, err := c.Server.startSession(c, req.PlayerID, charState.ID)
+	if err != nil {
+		log.Printf("Ошибка создания сессии для клиента %s: %v", c.Info.ID, err)
+	} else {
+		c.sendMessage(Message{
+			Type: MsgSession,
+			Payload: SessionInfo{
+				SessionID:   session.ID,
+				PlayerID:    req.PlayerID,
+				CharacterID: charState.ID,
+				GracePeriod: c.Server.Config.SessionGracePeriod.Milliseconds(),
+				ServerTime:  Now(),
+			},
+			Time: Now(),
+			Seq:  c.getNextSeq(),
+		})
 	}
 
 	// Получаем полное состояние локации для клиента
-	worldState := c.getFullWorldState(req.LocationID)
-	if worldState == nil {
-		c.sendError("location_not_found", "Локация не найдена")
+	snapshot := c.Server.Game.GetLocationSnapshot(charState.LocationID)
+	if snapshot == nil || snapshot.Location == nil {
+		c.joinFailed("location_not_found", "Локация не найдена")
 		return
 	}
+	frame := newDeltaFrame(charState.LocationID, snapshot).view(charState.X, c.Server.Config.ViewRadius)
+	worldState := c.buildWorldState(frame)
 
-	// Добавляем информацию о персонаже игрока
-	if charState != nil {
-		// Убедимся, что персонаж игрока есть в списке
-		found := false
-		for _, char := range worldState.Characters {
-			if char.ID == charState.ID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			worldState.Characters = append(worldState.Characters, charState)
-		}
-	}
-
-	// Отправляем состояние клиенту
-	msg := Message{
-		Type:    MsgWorldState,
-		Payload: worldState,
-		Time:    Now(),
-		Seq:     c.getNextSeq(),
+	// Убедимся, что персонаж игрока есть в списке
+	if _, found := frame.characters[charState.ID]; !found {
+		worldState.Characters = append(worldState.Characters, charState)
 	}
 
-	c.sendMessage(msg)
+	// Отправляем состояние клиенту, оно становится базовым для дельт
+	c.sendWorldState(worldState, frame)
+	c.sendInventory()
 
 	log.Printf("Клиент %s присоединился как игрок %d (персонаж %d) в локацию %d",
-		c.Info.ID, req.PlayerID, c.Info.CharacterID, req.LocationID)
+		c.Info.ID, req.PlayerID, c.Info.CharacterID, charState.LocationID)
+}
+
+// joinFailed отправляет ошибку присоединения и учитывает ее в метриках
+func (c *Client) joinFailed(code, message string) {
+	c.Server.metrics.joinFailures.With(code).Inc()
+	c.sendError(code, message)
 }
 
-// getFullWorldState получает полное состояние мира для клиента
-func (c *Client) getFullWorldState(locationID int) *WorldState {
-	return &WorldState{
-		PlayerID:   c.Info.PlayerID,
-		Location:   c.Server.Game.GetLocationState(locationID),
-		Characters: c.Server.Game.GetCharactersInLocation(locationID),
-		Creatures:  c.Server.Game.GetCreaturesInLocation(locationID),
-		Objects:    c.Server.Game.GetObjectsInLocation(locationID),
-		ServerTime: c.Server.Game.GetServerTime(),
+// handleResume возобновляет сессию после обрыва соединения: клиент получает
+// только сообщения с Seq больше last_seq, а если они уже вытеснены из буфера - полное состояние
+func (c *Client) handleResume(payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		c.sendError("parse_error", "Ошибка парсинга запроса")
+		return
+	}
+
+	var req ResumeRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.SessionID == "" {
+		c.sendError("invalid_request", "Неверный формат запроса возобновления")
+		return
+	}
+
+	if c.Info.PlayerID != 0 {
+		c.sendError("already_joined", "Клиент уже присоединился к игре")
+		return
+	}
+
+	session, err := c.Server.resumeSession(req.SessionID, c)
+	if err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+		return
+	}
+
+	// Уведомление не нумеруется, чтобы не сдвигать поток пропущенных сообщений
+	replayed, ok := session.replay(c, req.LastSeq, func(replayed int, ok bool) {
+		c.sendMessage(Message{
+			Type: MsgSessionResumed,
+			Payload: SessionResumed{
+				SessionID:   session.ID,
+				CharacterID: session.CharacterID,
+				LastSeq:     req.LastSeq,
+				Replayed:    replayed,
+				FullState:   !ok,
+				ServerTime:  Now(),
+			},
+			Time: Now(),
+		})
+	})
+
+	if !ok {
+		// Пропущенные сообщения потеряны - отправляем полное состояние
+		if locationID, x, found := c.Server.Game.GetCharacterPosition(session.CharacterID); found {
+			if frame := c.Server.createLocationFrame(locationID); frame != nil {
+				frame = frame.view(x, c.Server.Config.ViewRadius)
+				c.sendWorldState(c.buildWorldState(frame), frame)
+			}
+		}
 	}
+
+	log.Printf("Клиент %s возобновил сессию %s игрока %d (повторено %d сообщений)",
+		c.Info.ID, session.ID, session.PlayerID, replayed)
 }
 
 // handleMove обрабатывает движение
@@ -219,18 +351,7 @@ func (c *Client) handleMove(payload interface{}) {
 	}
 
 	// Отправляем подтверждение
-	msg := Message{
-		Type: MsgCharacterUpdate,
-		Payload: CharacterUpdate{
-			CharacterID: c.Info.CharacterID,
-			State:       c.Server.Game.GetCharacterByID(c.Info.CharacterID),
-			ServerTime:  Now(),
-		},
-		Time: Now(),
-		Seq:  c.getNextSeq(),
-	}
-
-	c.sendMessage(msg)
+	c.sendCharacterUpdate()
 }
 
 // handleStop обрабатывает остановку
@@ -271,6 +392,11 @@ func (c *Client) handleInteract(payload interface{}) {
 		return
 	}
 
+	// Длительное действие началось, результат придет в action_completed
+	if result == nil {
+		return
+	}
+
 	// Отправляем результат
 	msg := Message{
 		Type:    MsgInteractionResult,
@@ -282,26 +408,283 @@ func (c *Client) handleInteract(payload interface{}) {
 	c.sendMessage(msg)
 }
 
+// handleAttack обрабатывает удар по существу; результат приходит как interaction_result
+func (c *Client) handleAttack(payload interface{}) {
+	if c.Info.PlayerID == 0 {
+		c.sendError("not_joined", "Сначала нужно присоединиться к игре")
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		c.sendError("parse_error", "Ошибка парсинга запроса")
+		return
+	}
+
+	var req AttackRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.sendError("invalid_request", "Неверный формат запроса атаки")
+		return
+	}
+
+	result, err := c.Server.Game.HandleAttack(c.Info.PlayerID, req.CreatureID)
+	if err != nil {
+		c.sendError("attack_failed", err.Error())
+		return
+	}
+
+	msg := Message{
+		Type:    MsgInteractionResult,
+		Payload: result,
+		Time:    Now(),
+		Seq:     c.getNextSeq(),
+	}
+
+	c.sendMessage(msg)
+}
+
+// handleInventoryMove обрабатывает перекладывание предмета.
+// Новое содержимое инвентаря приходит в inventory_update.
+func (c *Client) handleInventoryMove(payload interface{}) {
+	var req InventoryMoveRequest
+	if !c.parseInventoryRequest(payload, &req) {
+		return
+	}
+
+	if err := c.Server.Game.HandleInventoryMove(c.Info.PlayerID, req.From, req.To); err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+	}
+}
+
+// handleInventorySplit обрабатывает разделение стопки
+func (c *Client) handleInventorySplit(payload interface{}) {
+	var req InventorySplitRequest
+	if !c.parseInventoryRequest(payload, &req) {
+		return
+	}
+
+	if err := c.Server.Game.HandleInventorySplit(c.Info.PlayerID, req.From, req.To, req.Count); err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+	}
+}
+
+// handleInventoryDrop обрабатывает выкладывание предметов на землю
+func (c *Client) handleInventoryDrop(payload interface{}) {
+	var req InventoryDropRequest
+	if !c.parseInventoryRequest(payload, &req) {
+		return
+	}
+
+	if err := c.Server.Game.HandleInventoryDrop(c.Info.PlayerID, req.Slot, req.Count); err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+	}
+}
+
+// handleEquip обрабатывает экипировку инструмента
+func (c *Client) handleEquip(payload interface{}) {
+	var req EquipRequest
+	if !c.parseInventoryRequest(payload, &req) {
+		return
+	}
+
+	if err := c.Server.Game.HandleEquip(c.Info.PlayerID, req.Slot); err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+	}
+}
+
+// handleUnequip обрабатывает снятие инструмента (без payload снимаются все)
+func (c *Client) handleUnequip(payload interface{}) {
+	var req UnequipRequest
+	if !c.parseInventoryRequest(payload, &req) {
+		return
+	}
+
+	if err := c.Server.Game.HandleUnequip(c.Info.PlayerID, req.ToolKind); err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+	}
+}
+
+// parseInventoryRequest проверяет, что клиент в игре, и разбирает запрос к инвентарю
+func (c *Client) parseInventoryRequest(payload interface{}, req interface{}) bool {
+	if c.Info.PlayerID == 0 {
+		c.sendError("not_joined", "Сначала нужно присоединиться к игре")
+		return false
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		c.sendError("parse_error", "Ошибка парсинга запроса")
+		return false
+	}
+
+	if err := json.Unmarshal(data, req); err != nil {
+		c.sendError("invalid_request", "Неверный формат запроса к инвентарю")
+		return false
+	}
+	return true
+}
+
+// sendInventory отправляет клиенту полное содержимое инвентаря его персонажа
+func (c *Client) sendInventory() {
+	inventory := c.Server.Game.GetInventory(c.Info.CharacterID)
+	if inventory == nil {
+		return
+	}
+
+	c.sendMessage(Message{
+		Type:    MsgInventoryUpdate,
+		Payload: inventory,
+		Time:    Now(),
+		Seq:     c.getNextSeq(),
+	})
+}
+
+// handleEat обрабатывает еду из инвентаря
+func (c *Client) handleEat(payload interface{}) {
+	if c.Info.PlayerID == 0 {
+		c.sendError("not_joined", "Сначала нужно присоединиться к игре")
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		c.sendError("parse_error", "Ошибка парсинга запроса")
+		return
+	}
+
+	var req EatRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.sendError("invalid_request", "Неверный формат запроса еды")
+		return
+	}
+
+	if err := c.Server.Game.HandleEat(c.Info.PlayerID, req.Slot); err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+		return
+	}
+
+	c.sendCharacterUpdate()
+}
+
+// handleDrink обрабатывает питье из ручья или реки рядом с персонажем
+func (c *Client) handleDrink() {
+	if c.Info.PlayerID == 0 {
+		c.sendError("not_joined", "Сначала нужно присоединиться к игре")
+		return
+	}
+
+	if err := c.Server.Game.HandleDrink(c.Info.PlayerID); err != nil {
+		c.sendError(GetErrorCode(err), err.Error())
+		return
+	}
+
+	c.sendCharacterUpdate()
+}
+
+// sendCharacterUpdate отправляет клиенту текущее состояние его персонажа
+func (c *Client) sendCharacterUpdate() {
+	msg := Message{
+		Type: MsgCharacterUpdate,
+		Payload: CharacterUpdate{
+			CharacterID: c.Info.CharacterID,
+			State:       c.Server.Game.GetCharacterByID(c.Info.CharacterID),
+			ServerTime:  Now(),
+		},
+		Time: Now(),
+		Seq:  c.getNextSeq(),
+	}
+
+	c.sendMessage(msg)
+}
+
 // sendMessage отправляет структурированное сообщение
 func (c *Client) sendMessage(msg Message) {
-	data, err := json.Marshal(msg)
+	// Нумерованные сообщения сохраняются в сессии для повтора после переподключения
+	if session := c.session.Load(); session != nil && msg.Seq != 0 {
+		if !session.record(c, msg) {
+			return
+		}
+	}
+
+	c.writeMessage(msg)
+}
+
+// writeMessage кодирует сообщение кодеком соединения и ставит его в очередь отправки
+func (c *Client) writeMessage(msg Message) {
+	data, err := c.codec.Encode(msg)
 	if err != nil {
 		log.Printf("Ошибка маршалинга сообщения: %v", err)
 		return
 	}
 
-	c.sendRaw(data)
+	c.sendRaw(msg.Type, data)
 }
 
-// sendRaw отправляет сырые данные
-func (c *Client) sendRaw(data []byte) {
+// sendRaw ставит закодированное сообщение типа msgType в очередь отправки
+func (c *Client) sendRaw(msgType MessageType, data []byte) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if c.closed {
+		return
+	}
+
 	select {
 	case c.Send <- data:
+		c.Server.metrics.messagesOut.With(string(msgType)).Inc()
 	default:
+		c.Server.metrics.dropped.With(string(msgType)).Inc()
 		log.Printf("Канал отправки клиента %s переполнен", c.Info.ID)
 	}
 }
 
+// closeSend закрывает канал отправки (повторный вызов безопасен)
+func (c *Client) closeSend() {
+	c.Close(websocket.CloseNormalClosure, "")
+}
+
+// Close закрывает соединение с клиентом: уже поставленные в очередь сообщения
+// будут отправлены, после них клиент получит close frame с кодом code
+func (c *Client) Close(code int, reason string) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		c.closeCode = code
+		c.closeText = reason
+		close(c.Send)
+	}
+}
+
+// isClosed проверяет, закрыто ли соединение сервером
+func (c *Client) isClosed() bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.closed
+}
+
+// GetInfo возвращает копию информации о клиенте для чтения из других горутин
+func (c *Client) GetInfo() ClientInfo {
+	c.infoMu.RLock()
+	defer c.infoMu.RUnlock()
+	return *c.Info
+}
+
+// setLocation запоминает локацию, в которую перешел персонаж клиента
+func (c *Client) setLocation(locationID int) {
+	c.infoMu.Lock()
+	c.Info.LocationID = locationID
+	c.infoMu.Unlock()
+}
+
+// touch обновляет время последней активности клиента
+func (c *Client) touch() {
+	c.infoMu.Lock()
+	c.Info.LastActivity = time.Now()
+	c.infoMu.Unlock()
+}
+
 // sendError отправляет сообщение об ошибке
 func (c *Client) sendError(code, message string) {
 	msg := Message{
@@ -334,7 +717,10 @@ func (c *Client) sendWelcome() {
 	c.sendMessage(msg)
 }
 
+// getNextSeq выдает следующий номер сообщения (после join нумерация ведется сессией)
 func (c *Client) getNextSeq() int64 {
-	c.sequence++
-	return c.sequence
+	if session := c.session.Load(); session != nil {
+		return session.nextSeq()
+	}
+	return atomic.AddInt64(&c.sequence, 1)
 }
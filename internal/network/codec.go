@@ -0,0 +1,111 @@
+package network
+
+import (
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+)
+
+// Codec - кодирование сообщений протокола для одного соединения.
+// Кодек выбирается клиентом через подпротокол WebSocket (Sec-WebSocket-Protocol).
+type Codec interface {
+	// Subprotocol - имя подпротокола WebSocket, которым клиент выбирает кодек
+	Subprotocol() string
+	// FrameType - тип кадров WebSocket (websocket.TextMessage или websocket.BinaryMessage)
+	FrameType() int
+	// Encode кодирует сообщение сервера
+	Encode(msg Message) ([]byte, error)
+	// Decode разбирает сообщение клиента. Payload разбирается в обобщенные значения
+	// (map[string]interface{}, []interface{}, числа, строки), как в encoding/json
+	Decode(data []byte) (Message, error)
+}
+
+// Подпротоколы WebSocket
+const (
+	SubprotocolJSON    = "loil.json"
+	SubprotocolMsgpack = "loil.msgpack"
+)
+
+// codecs - поддерживаемые кодеки в порядке предпочтения сервера
+var codecs = []Codec{MsgpackCodec{}, JSONCodec{}}
+
+// Subprotocols возвращает имена подпротоколов поддерживаемых кодеков
+func Subprotocols() []string {
+	names := make([]string, len(codecs))
+	for i, codec := range codecs {
+		names[i] = codec.Subprotocol()
+	}
+	return names
+}
+
+// CodecFor возвращает кодек выбранного подпротокола. Без подпротокола используется JSON.
+func CodecFor(subprotocol string) Codec {
+	for _, codec := range codecs {
+		if codec.Subprotocol() == subprotocol {
+			return codec
+		}
+	}
+	return JSONCodec{}
+}
+
+// JSONCodec - текстовый протокол на encoding/json (по умолчанию)
+type JSONCodec struct{}
+
+func (JSONCodec) Subprotocol() string { return SubprotocolJSON }
+
+func (JSONCodec) FrameType() int { return websocket.TextMessage }
+
+func (JSONCodec) Encode(msg Message) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+func (JSONCodec) Decode(data []byte) (Message, error) {
+	var msg Message
+	err := json.Unmarshal(data, &msg)
+	return msg, err
+}
+
+// MsgpackCodec - двоичный протокол MessagePack с теми же именами полей, что и в JSON.
+// Числа с плавающей точкой без дробной части передаются как целые.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Subprotocol() string { return SubprotocolMsgpack }
+
+func (MsgpackCodec) FrameType() int { return websocket.BinaryMessage }
+
+func (MsgpackCodec) Encode(msg Message) ([]byte, error) {
+	return MarshalMsgpack(msg)
+}
+
+func (MsgpackCodec) Decode(data []byte) (Message, error) {
+	var msg Message
+
+	value, err := UnmarshalMsgpack(data)
+	if err != nil {
+		return msg, err
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return msg, errMsgpack("сообщение должно быть map")
+	}
+
+	msgType, _ := fields["type"].(string)
+	msg.Type = MessageType(msgType)
+	msg.Payload = fields["payload"]
+	msg.Seq = msgpackInt(fields["seq"])
+	msg.Time = msgpackInt(fields["time"])
+	return msg, nil
+}
+
+// msgpackInt приводит разобранное число к int64
+func msgpackInt(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
@@ -0,0 +1,354 @@
+package network
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testServerTime - фиксированное время для тестовых сообщений
+const testServerTime = 1700000000000
+
+// sampleLocation - локация шириной width с заполненными слоями
+func sampleLocation(width int) *LocationState {
+	loc := &LocationState{
+		ID:         1,
+		Name:       "Лесная дорога",
+		Width:      width,
+		Foreground: make([]int, width),
+		Road:       make([]int, width),
+		Ground:     make([]int, width),
+		Background: make([]int, width),
+		LastUpdate: testServerTime,
+	}
+	for i := 0; i < width; i++ {
+		loc.Road[i] = 1 + i%2
+		loc.Ground[i] = 1 + i%5
+		if i%7 == 0 {
+			loc.Background[i] = 6 + i%3
+		}
+		if i%11 == 0 {
+			loc.Foreground[i] = 5
+		}
+	}
+	loc.Foreground[3] = -1001 // Метка существа
+	return loc
+}
+
+func sampleCharacter(id int) *CharacterState {
+	return &CharacterState{
+		ID:         id,
+		Name:       fmt.Sprintf("Игрок %d", id),
+		LocationID: 1,
+		X:          float64(id) + 0.37,
+		Direction:  1,
+		Speed:      0.7,
+		Health:     87,
+		MaxHealth:  100,
+		Hunger:     12,
+		Thirst:     30,
+		Stamina:    64,
+		Controlled: id,
+		Action:     "chop",
+		LastUpdate: testServerTime,
+	}
+}
+
+func sampleCreature(id int) *CreatureState {
+	return &CreatureState{
+		ID:         id,
+		TypeID:     2,
+		Name:       "Беляк",
+		LocationID: 1,
+		X:          float64(id%40) + 0.5,
+		Health:     24,
+		MaxHealth:  30,
+		Hunger:     60,
+		Behavior:   "wander",
+		LastUpdate: testServerTime,
+	}
+}
+
+func sampleObject(id int) *ObjectState {
+	return &ObjectState{
+		ID:            id,
+		TypeID:        5,
+		LocationID:    1,
+		X:             id % 64,
+		Durability:    20,
+		MaxDurability: 30,
+		GrowthStage:   75,
+		LastUpdate:    testServerTime,
+	}
+}
+
+// sampleWorldState - полное состояние локации: 64 клетки, 5 персонажей, 8 существ, 20 объектов
+func sampleWorldState() *WorldState {
+	state := &WorldState{
+		PlayerID:   1,
+		Location:   sampleLocation(64),
+		LayerHash:  "9c1f0a3b5d7e2f41",
+		ServerTime: testServerTime,
+		Chat: []*ChatMessage{
+			{Scope: ChatLocal, FromPlayer: 2, FromCharacter: 2, FromName: "Игрок 2", LocationID: 1, Text: "Привет!", ServerTime: testServerTime},
+		},
+	}
+	for i := 1; i <= 5; i++ {
+		state.Characters = append(state.Characters, sampleCharacter(i))
+	}
+	for i := 1; i <= 8; i++ {
+		state.Creatures = append(state.Creatures, sampleCreature(1000+i))
+	}
+	for i := 1; i <= 20; i++ {
+		state.Objects = append(state.Objects, sampleObject(100+i))
+	}
+	return state
+}
+
+// sampleLocationUpdate - типичное обновление: двигаются 2 персонажа и 3 существа, меняются 2 клетки слоя
+func sampleLocationUpdate() *LocationUpdate {
+	return &LocationUpdate{
+		LocationID: 1,
+		BaseSeq:    41,
+		Characters: []*CharacterState{sampleCharacter(1), sampleCharacter(2)},
+		Creatures:  []*CreatureState{sampleCreature(1001), sampleCreature(1002), sampleCreature(1003)},
+		Objects:    []*ObjectState{sampleObject(105)},
+		RemovedObjects: []int{
+			110,
+		},
+		Layers: &LayerDiff{
+			BaseHash:   "9c1f0a3b5d7e2f41",
+			Foreground: []CellChange{{Index: 3, Value: 0}, {Index: 5, Value: -1001}},
+		},
+		LayerHash:  "0b6d3a9e1c2f4e88",
+		ServerTime: testServerTime,
+	}
+}
+
+// samplePayloads - пример полезной нагрузки для каждого типа сообщения протокола
+func samplePayloads() map[MessageType]interface{} {
+	result := &InteractionResult{
+		Success:     true,
+		ObjectID:    100,
+		Interaction: "collect",
+		Items:       []InventoryItem{{ItemID: 5, Count: 3, Name: "Малина"}},
+		Object:      sampleObject(100),
+		ServerTime:  testServerTime,
+	}
+
+	return map[MessageType]interface{}{
+		MsgWorldState:      sampleWorldState(),
+		MsgLocationUpdate:  sampleLocationUpdate(),
+		MsgCharacterUpdate: &CharacterUpdate{CharacterID: 1, State: sampleCharacter(1), ServerTime: testServerTime},
+		MsgInteractionResult: &InteractionResult{
+			Success: true, CreatureID: 1001, Creature: sampleCreature(1001), Damage: 20, Killed: true,
+			ContainerID: 300, ServerTime: testServerTime,
+		},
+		MsgError: &ErrorMessage{Code: "tool_required", Message: "Нужен инструмент: axe"},
+		MsgPing:  map[string]int64{"server_time": testServerTime},
+		MsgActionStarted: &ActionState{
+			CharacterID: 1, Action: "chop", ObjectID: 104, InteractionIdx: 0, Duration: 30, ServerTime: testServerTime,
+		},
+		MsgActionProgress: &ActionState{
+			CharacterID: 1, Action: "chop", ObjectID: 104, Duration: 30, Elapsed: 12.5, Progress: 12.5 / 30, ServerTime: testServerTime,
+		},
+		MsgActionCompleted: &ActionState{
+			CharacterID: 1, Action: "collect", ObjectID: 100, Duration: 5, Elapsed: 5, Progress: 1, Result: result, ServerTime: testServerTime,
+		},
+		MsgServerShutdown: &ShutdownMessage{Reason: "Сервер останавливается", ServerTime: testServerTime},
+		MsgSession: &SessionInfo{
+			SessionID: "70c5a9f0870adbc512438ccfd06fe39a", PlayerID: 1, CharacterID: 2, GracePeriod: 30000, ServerTime: testServerTime,
+		},
+		MsgSessionResumed: &SessionResumed{
+			SessionID: "70c5a9f0870adbc512438ccfd06fe39a", CharacterID: 2, LastSeq: 11, Replayed: 10, ServerTime: testServerTime,
+		},
+		MsgVisibility: &VisibilityUpdate{
+			LocationID: 1,
+			Entered:    []EntityRef{{Kind: EntityCreature, ID: 1001}},
+			Left:       []EntityRef{{Kind: EntityCharacter, ID: 3}, {Kind: EntityObject, ID: 110}},
+			ServerTime: testServerTime,
+		},
+		MsgCombat: &CombatUpdate{
+			LocationID: 1, AttackerKind: EntityCreature, AttackerID: 1002, TargetKind: EntityCharacter, TargetID: 1,
+			Damage: 15, Health: 72, MaxHealth: 100, ServerTime: testServerTime,
+		},
+		MsgCreatureDied: &CreatureDied{
+			LocationID: 1, CreatureID: 1001, TypeID: 2, Name: "Беляк", X: 8.25, KillerKind: EntityCharacter, KillerID: 1,
+			ContainerID: 300, ServerTime: testServerTime,
+		},
+		MsgCharacterDied: &CharacterDied{
+			LocationID: 1, CharacterID: 1, Name: "Игрок 1", X: 4, Cause: "hunger", ContainerID: 301, ServerTime: testServerTime,
+		},
+		MsgInventoryUpdate: &InventoryUpdate{
+			CharacterID: 1,
+			Size:        20,
+			Slots:       map[int]InventoryItem{0: {ItemID: 5, Count: 3, Name: "Малина"}, 4: {ItemID: 6, Count: 10, Name: "Дубовое бревно"}},
+			Equipped:    map[string]InventoryItem{"axe": {ItemID: 8, Count: 1, Name: "Топор"}},
+			ServerTime:  testServerTime,
+		},
+		MsgChat: &ChatMessage{
+			Scope: ChatWhisper, FromPlayer: 1, FromCharacter: 1, FromName: "Игрок 1", ToPlayer: 2, Text: "Встретимся у реки", ServerTime: testServerTime,
+		},
+
+		MsgJoin:           &JoinRequest{PlayerID: 1, LocationID: 1, Token: "eyJwbGF5ZXJfaWQiOjF9.c2lnbmF0dXJl"},
+		MsgMove:           &MoveRequest{Direction: -1, Vertical: 1},
+		MsgStop:           nil,
+		MsgInteract:       &InteractRequest{ObjectID: 100, InteractionIdx: 1},
+		MsgPong:           nil,
+		MsgAck:            &AckRequest{Seq: 42},
+		MsgResume:         &ResumeRequest{SessionID: "70c5a9f0870adbc512438ccfd06fe39a", LastSeq: 11},
+		MsgEmote:          &EmoteRequest{Emote: "wave"},
+		MsgEat:            &EatRequest{Slot: 0},
+		MsgDrink:          nil,
+		MsgAttack:         &AttackRequest{CreatureID: 1001},
+		MsgInventoryMove:  &InventoryMoveRequest{From: 0, To: 5},
+		MsgInventorySplit: &InventorySplitRequest{From: 4, To: 6, Count: 3},
+		MsgInventoryDrop:  &InventoryDropRequest{Slot: 6},
+		MsgEquip:          &EquipRequest{Slot: 2},
+		MsgUnequip:        &UnequipRequest{ToolKind: "axe"},
+	}
+}
+
+// allMessageTypes - все типы сообщений протокола (при добавлении типа добавьте его и в samplePayloads)
+var allMessageTypes = []MessageType{
+	MsgWorldState, MsgLocationUpdate, MsgCharacterUpdate, MsgInteractionResult, MsgError, MsgPing,
+	MsgActionStarted, MsgActionProgress, MsgActionCompleted, MsgServerShutdown, MsgSession, MsgSessionResumed,
+	MsgVisibility, MsgCombat, MsgCreatureDied, MsgCharacterDied, MsgInventoryUpdate, MsgChat,
+	MsgJoin, MsgMove, MsgStop, MsgInteract, MsgPong, MsgAck, MsgResume, MsgEmote, MsgEat, MsgDrink, MsgAttack,
+	MsgInventoryMove, MsgInventorySplit, MsgInventoryDrop, MsgEquip, MsgUnequip,
+}
+
+// normalizeNumbers приводит числа к float64, как их разбирает encoding/json.
+// MessagePack передает целые значения целыми, поэтому без приведения сравнение было бы по типам.
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	}
+	return value
+}
+
+func TestMsgpackRoundTripMatchesJSON(t *testing.T) {
+	payloads := samplePayloads()
+	if len(payloads) != len(allMessageTypes) {
+		t.Errorf("примеров %d, типов сообщений %d", len(payloads), len(allMessageTypes))
+	}
+
+	for _, msgType := range allMessageTypes {
+		t.Run(string(msgType), func(t *testing.T) {
+			payload, ok := payloads[msgType]
+			if !ok {
+				t.Fatalf("нет примера полезной нагрузки для %s", msgType)
+			}
+			msg := Message{Type: msgType, Payload: payload, Seq: 42, Time: testServerTime}
+
+			jsonData, err := JSONCodec{}.Encode(msg)
+			if err != nil {
+				t.Fatalf("JSON Encode: %v", err)
+			}
+			viaJSON, err := JSONCodec{}.Decode(jsonData)
+			if err != nil {
+				t.Fatalf("JSON Decode: %v", err)
+			}
+
+			msgpackData, err := MsgpackCodec{}.Encode(msg)
+			if err != nil {
+				t.Fatalf("Msgpack Encode: %v", err)
+			}
+			viaMsgpack, err := MsgpackCodec{}.Decode(msgpackData)
+			if err != nil {
+				t.Fatalf("Msgpack Decode: %v", err)
+			}
+			viaMsgpack.Payload = normalizeNumbers(viaMsgpack.Payload)
+
+			if !reflect.DeepEqual(viaMsgpack, viaJSON) {
+				t.Errorf("MessagePack и JSON разобрали сообщение по-разному:\nmsgpack: %#v\njson:    %#v", viaMsgpack, viaJSON)
+			}
+			if len(msgpackData) > len(jsonData) {
+				t.Errorf("MessagePack длиннее JSON: %d > %d байт", len(msgpackData), len(jsonData))
+			}
+		})
+	}
+}
+
+func TestMsgpackFloats(t *testing.T) {
+	for _, value := range []float64{0, 5, -3, 0.7, 12.37, -0.25, 1 << 60} {
+		data, err := MarshalMsgpack(value)
+		if err != nil {
+			t.Fatalf("%v: %v", value, err)
+		}
+		decoded, err := UnmarshalMsgpack(data)
+		if err != nil {
+			t.Fatalf("%v: %v", value, err)
+		}
+		if got := normalizeNumbers(decoded); got != value {
+			t.Errorf("%v: разобрано как %v", value, got)
+		}
+	}
+}
+
+func TestMsgpackDecodeErrors(t *testing.T) {
+	tests := map[string][]byte{
+		"пустые данные":      {},
+		"обрезанная строка":  {0xa5, 'a', 'b'},
+		"обрезанный массив":  {0xdc, 0xff, 0xff, 0x01},
+		"не map":             {0x93, 0x01, 0x02, 0x03},
+		"неизвестный формат": {0xc1},
+	}
+
+	for name, data := range tests {
+		if _, err := (MsgpackCodec{}).Decode(data); err == nil {
+			t.Errorf("%s: ожидали ошибку", name)
+		}
+	}
+}
+
+// benchmarkCodec измеряет кодирование и разбор сообщения кодеком и сообщает размер сообщения в байтах
+func benchmarkCodec(b *testing.B, codec Codec, msg Message) {
+	data, err := codec.Encode(msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Encode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := codec.Encode(msg); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(len(data)), "bytes/msg")
+	})
+
+	b.Run("Decode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := codec.Decode(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(len(data)), "bytes/msg")
+	})
+}
+
+func BenchmarkLocationUpdate(b *testing.B) {
+	msg := Message{Type: MsgLocationUpdate, Payload: sampleLocationUpdate(), Seq: 42, Time: testServerTime}
+	b.Run("JSON", func(b *testing.B) { benchmarkCodec(b, JSONCodec{}, msg) })
+	b.Run("Msgpack", func(b *testing.B) { benchmarkCodec(b, MsgpackCodec{}, msg) })
+}
+
+func BenchmarkWorldState(b *testing.B) {
+	msg := Message{Type: MsgWorldState, Payload: sampleWorldState(), Seq: 42, Time: testServerTime}
+	b.Run("JSON", func(b *testing.B) { benchmarkCodec(b, JSONCodec{}, msg) })
+	b.Run("Msgpack", func(b *testing.B) { benchmarkCodec(b, MsgpackCodec{}, msg) })
+}
@@ -0,0 +1,485 @@
+package network
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"slices"
+	"sync"
+)
+
+const (
+	// deltaHistorySize - сколько отправленных кадров хранится в ожидании подтверждения
+	deltaHistorySize = 64
+	// deltaMaxUnacked - после стольких обновлений без подтверждения базовое состояние считается потерянным
+	deltaMaxUnacked = 50
+)
+
+// deltaFrame - состояние локации, отправленное клиенту с определенным Seq.
+// Кадр строится один раз на локацию за рассылку и не изменяется после создания.
+type deltaFrame struct {
+	locationID int
+	snapshot   *LocationSnapshot
+	characters map[int]*CharacterState
+	creatures  map[int]*CreatureState
+	objects    map[int]*ObjectState
+	layerHash  string
+}
+
+// newDeltaFrame строит кадр из снимка локации
+func newDeltaFrame(locationID int, snapshot *LocationSnapshot) *deltaFrame {
+	frame := &deltaFrame{
+		locationID: locationID,
+		snapshot:   snapshot,
+		characters: make(map[int]*CharacterState, len(snapshot.Characters)),
+		creatures:  make(map[int]*CreatureState, len(snapshot.Creatures)),
+		objects:    make(map[int]*ObjectState, len(snapshot.Objects)),
+		layerHash:  LayerHash(snapshot.Location),
+	}
+
+	for _, char := range snapshot.Characters {
+		frame.characters[char.ID] = char
+	}
+	for _, creature := range snapshot.Creatures {
+		frame.creatures[creature.ID] = creature
+	}
+	for _, obj := range snapshot.Objects {
+		frame.objects[obj.ID] = obj
+	}
+
+	return frame
+}
+
+// view возвращает кадр, ограниченный областью видимости: сущности в радиусе radius
+// клеток от позиции x. Метки существ (-ID) в слое переднего плана за пределами
+// видимости скрываются. Если radius <= 0, видна вся локация.
+func (f *deltaFrame) view(x, radius float64) *deltaFrame {
+	if radius <= 0 {
+		return f
+	}
+
+	visible := func(pos float64) bool {
+		return pos >= x-radius && pos <= x+radius
+	}
+
+	snapshot := &LocationSnapshot{
+		Location:   f.snapshot.Location,
+		ServerTime: f.snapshot.ServerTime,
+	}
+	for _, char := range f.snapshot.Characters {
+		if visible(char.X) {
+			snapshot.Characters = append(snapshot.Characters, char)
+		}
+	}
+	for _, creature := range f.snapshot.Creatures {
+		if visible(creature.X) {
+			snapshot.Creatures = append(snapshot.Creatures, creature)
+		}
+	}
+	for _, obj := range f.snapshot.Objects {
+		if visible(float64(obj.X)) {
+			snapshot.Objects = append(snapshot.Objects, obj)
+		}
+	}
+
+	// Слой копируется, только если в нем есть скрываемые метки
+	var foreground []int
+	for pos, val := range f.snapshot.Location.Foreground {
+		if val < 0 && !visible(float64(pos)) {
+			if foreground == nil {
+				foreground = slices.Clone(f.snapshot.Location.Foreground)
+			}
+			foreground[pos] = 0
+		}
+	}
+	if foreground != nil {
+		loc := *f.snapshot.Location
+		loc.Foreground = foreground
+		snapshot.Location = &loc
+	}
+
+	return newDeltaFrame(f.locationID, snapshot)
+}
+
+// visibilityChanges находит сущности, которые появились в кадре cur или пропали из него
+// по сравнению с кадром prev
+func visibilityChanges(prev, cur *deltaFrame) *VisibilityUpdate {
+	update := &VisibilityUpdate{
+		LocationID: cur.locationID,
+		ServerTime: cur.snapshot.ServerTime,
+	}
+
+	for _, id := range sortedIDs(cur.characters) {
+		if _, ok := prev.characters[id]; !ok {
+			update.Entered = append(update.Entered, EntityRef{Kind: EntityCharacter, ID: id})
+		}
+	}
+	for _, id := range sortedIDs(prev.characters) {
+		if _, ok := cur.characters[id]; !ok {
+			update.Left = append(update.Left, EntityRef{Kind: EntityCharacter, ID: id})
+		}
+	}
+
+	for _, id := range sortedIDs(cur.creatures) {
+		if _, ok := prev.creatures[id]; !ok {
+			update.Entered = append(update.Entered, EntityRef{Kind: EntityCreature, ID: id})
+		}
+	}
+	for _, id := range sortedIDs(prev.creatures) {
+		if _, ok := cur.creatures[id]; !ok {
+			update.Left = append(update.Left, EntityRef{Kind: EntityCreature, ID: id})
+		}
+	}
+
+	for _, id := range sortedIDs(cur.objects) {
+		if _, ok := prev.objects[id]; !ok {
+			update.Entered = append(update.Entered, EntityRef{Kind: EntityObject, ID: id})
+		}
+	}
+	for _, id := range sortedIDs(prev.objects) {
+		if _, ok := cur.objects[id]; !ok {
+			update.Left = append(update.Left, EntityRef{Kind: EntityObject, ID: id})
+		}
+	}
+
+	return update
+}
+
+// sortedIDs возвращает ID сущностей кадра по возрастанию
+func sortedIDs[T any](entities map[int]T) []int {
+	ids := make([]int, 0, len(entities))
+	for id := range entities {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// LayerHash вычисляет хеш слоев локации (FNV-1a по всем четырем слоям)
+func LayerHash(loc *LocationState) string {
+	if loc == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, layer := range [][]int{loc.Foreground, loc.Road, loc.Ground, loc.Background} {
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(layer)))
+		h.Write(buf[:])
+		for _, val := range layer {
+			binary.LittleEndian.PutUint64(buf[:], uint64(val))
+			h.Write(buf[:])
+		}
+	}
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// diffFrames строит обновление, переводящее клиента из base в cur
+func diffFrames(base, cur *deltaFrame) *LocationUpdate {
+	update := &LocationUpdate{
+		LocationID: cur.locationID,
+		LayerHash:  cur.layerHash,
+		ServerTime: cur.snapshot.ServerTime,
+	}
+
+	for id, char := range cur.characters {
+		if old, ok := base.characters[id]; !ok || !sameCharacter(old, char) {
+			update.Characters = append(update.Characters, char)
+		}
+	}
+	for id := range base.characters {
+		if _, ok := cur.characters[id]; !ok {
+			update.RemovedCharacters = append(update.RemovedCharacters, id)
+		}
+	}
+
+	for id, creature := range cur.creatures {
+		if old, ok := base.creatures[id]; !ok || !sameCreature(old, creature) {
+			update.Creatures = append(update.Creatures, creature)
+		}
+	}
+	for id := range base.creatures {
+		if _, ok := cur.creatures[id]; !ok {
+			update.RemovedCreatures = append(update.RemovedCreatures, id)
+		}
+	}
+
+	for id, obj := range cur.objects {
+		if old, ok := base.objects[id]; !ok || !sameObject(old, obj) {
+			update.Objects = append(update.Objects, obj)
+		}
+	}
+	for id := range base.objects {
+		if _, ok := cur.objects[id]; !ok {
+			update.RemovedObjects = append(update.RemovedObjects, id)
+		}
+	}
+
+	if base.layerHash != cur.layerHash {
+		update.Layers = diffLayers(base.snapshot.Location, cur.snapshot.Location)
+		update.Layers.BaseHash = base.layerHash
+	}
+
+	return update
+}
+
+// isEmpty проверяет, что обновление не содержит изменений
+func (u *LocationUpdate) isEmpty() bool {
+	return len(u.Characters) == 0 && len(u.Creatures) == 0 && len(u.Objects) == 0 &&
+		len(u.RemovedCharacters) == 0 && len(u.RemovedCreatures) == 0 && len(u.RemovedObjects) == 0 &&
+		u.Layers == nil
+}
+
+// diffLayers находит измененные клетки слоев
+func diffLayers(base, cur *LocationState) *LayerDiff {
+	return &LayerDiff{
+		Foreground: diffLayer(base.Foreground, cur.Foreground),
+		Road:       diffLayer(base.Road, cur.Road),
+		Ground:     diffLayer(base.Ground, cur.Ground),
+		Background: diffLayer(base.Background, cur.Background),
+	}
+}
+
+func diffLayer(base, cur []int) []CellChange {
+	var changes []CellChange
+	for i, val := range cur {
+		if i >= len(base) || base[i] != val {
+			changes = append(changes, CellChange{Index: i, Value: val})
+		}
+	}
+	return changes
+}
+
+// Сравнение состояний без учета времени последнего обновления
+func sameCharacter(a, b *CharacterState) bool {
+	x := *a
+	x.LastUpdate = b.LastUpdate
+	return x == *b
+}
+
+func sameCreature(a, b *CreatureState) bool {
+	x := *a
+	x.LastUpdate = b.LastUpdate
+	return x == *b
+}
+
+func sameObject(a, b *ObjectState) bool {
+	x := *a
+	x.LastUpdate = b.LastUpdate
+	return x == *b
+}
+
+// deltaTracker - базовое состояние клиента и история неподтвержденных кадров
+type deltaTracker struct {
+	mu       sync.Mutex
+	acked    *deltaFrame           // Последний подтвержденный клиентом кадр
+	ackedSeq int64                 // Seq подтвержденного кадра
+	fullSeq  int64                 // Seq последнего отправленного полного состояния
+	history  map[int64]*deltaFrame // Отправленные кадры по Seq
+	sinceAck int                   // Сколько обновлений отправлено без подтверждения
+	visible  *deltaFrame           // Область видимости на прошлом обновлении (для событий входа и выхода)
+}
+
+func newDeltaTracker() *deltaTracker {
+	return &deltaTracker{
+		history: make(map[int64]*deltaFrame),
+	}
+}
+
+// record запоминает отправленный кадр
+func (t *deltaTracker) record(seq int64, frame *deltaFrame) {
+	t.history[seq] = frame
+
+	// Удаляем самые старые кадры, если история переполнена
+	for len(t.history) > deltaHistorySize {
+		oldest := seq
+		for s := range t.history {
+			if s < oldest {
+				oldest = s
+			}
+		}
+		delete(t.history, oldest)
+	}
+}
+
+// reset сбрасывает базовое состояние (например, после отправки полного состояния)
+func (t *deltaTracker) reset(seq int64, frame *deltaFrame) {
+	t.acked = nil
+	t.ackedSeq = 0
+	t.fullSeq = seq
+	t.sinceAck = 0
+	clear(t.history)
+	t.record(seq, frame)
+}
+
+// ack подтверждает получение кадра с номером seq
+func (t *deltaTracker) ack(seq int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frame, ok := t.history[seq]
+	if !ok {
+		return false
+	}
+
+	t.acked = frame
+	t.ackedSeq = seq
+	t.sinceAck = 0
+
+	// Кадры старше подтвержденного больше не понадобятся
+	for s := range t.history {
+		if s < seq {
+			delete(t.history, s)
+		}
+	}
+
+	return true
+}
+
+// deltaState возвращает базовое состояние дельт клиента. После join или resume
+// оно принадлежит сессии, поэтому переходит к новому соединению вместе с ней.
+func (c *Client) deltaState() *deltaTracker {
+	if session := c.session.Load(); session != nil {
+		return session.delta
+	}
+	return c.delta
+}
+
+// sendLocationUpdate отправляет клиенту изменения относительно подтвержденного состояния
+// (или полного состояния, подтверждение которого еще не пришло).
+// Если базовое состояние потеряно, клиент получает полное world_state.
+func (c *Client) sendLocationUpdate(frame *deltaFrame) {
+	// Сессию продолжило другое соединение - базовое состояние теперь принадлежит ему
+	if session := c.session.Load(); session != nil && !session.owns(c) {
+		return
+	}
+
+	t := c.deltaState()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	base, baseSeq := t.acked, t.ackedSeq
+	if base == nil {
+		// Полное состояние еще не подтверждено. Сообщения приходят клиенту по порядку,
+		// поэтому изменения отправляются относительно него, чтобы картинка не замирала до подтверждения
+		base, baseSeq = t.history[t.fullSeq], t.fullSeq
+	}
+
+	if base == nil || base.locationID != frame.locationID {
+		// Клиент сменил локацию - старое базовое состояние не подходит
+		c.sendWorldStateLocked(c.buildWorldState(frame), frame)
+		return
+	}
+
+	if t.sinceAck >= deltaMaxUnacked {
+		// Клиент давно не подтверждал обновления - базовое состояние потеряно
+		log.Printf("Клиент %s не подтверждает обновления, отправляем полное состояние", c.Info.ID)
+		c.sendWorldStateLocked(c.buildWorldState(frame), frame)
+		return
+	}
+
+	update := diffFrames(base, frame)
+	if update.isEmpty() {
+		return
+	}
+
+	seq := c.getNextSeq()
+	update.BaseSeq = baseSeq
+
+	c.sendMessage(Message{
+		Type:    MsgLocationUpdate,
+		Payload: update,
+		Time:    Now(),
+		Seq:     seq,
+	})
+
+	t.record(seq, frame)
+	t.sinceAck++
+
+	// События входа и выхода отправляются только вслед за отправленным обновлением,
+	// чтобы данные вошедших сущностей пришли раньше
+	c.sendVisibilityLocked(frame)
+}
+
+// sendVisibilityLocked сообщает клиенту, какие сущности вошли в область видимости
+// или покинули ее с прошлого отправленного обновления. Если обновление не отправлялось,
+// область видимости не сдвигается и изменения уйдут со следующим обновлением.
+func (c *Client) sendVisibilityLocked(frame *deltaFrame) {
+	t := c.deltaState()
+	prev := t.visible
+	t.visible = frame
+	if prev == nil || prev.locationID != frame.locationID {
+		return
+	}
+
+	update := visibilityChanges(prev, frame)
+	if len(update.Entered) == 0 && len(update.Left) == 0 {
+		return
+	}
+
+	c.sendMessage(Message{
+		Type:    MsgVisibility,
+		Payload: update,
+		Time:    Now(),
+		Seq:     c.getNextSeq(),
+	})
+}
+
+// sendWorldState отправляет полное состояние и делает его новым базовым кадром
+func (c *Client) sendWorldState(worldState *WorldState, frame *deltaFrame) {
+	t := c.deltaState()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.sendWorldStateLocked(worldState, frame)
+}
+
+func (c *Client) sendWorldStateLocked(worldState *WorldState, frame *deltaFrame) {
+	seq := c.getNextSeq()
+	c.sendMessage(Message{
+		Type:    MsgWorldState,
+		Payload: worldState,
+		Time:    Now(),
+		Seq:     seq,
+	})
+
+	t := c.deltaState()
+	t.reset(seq, frame)
+	// Полное состояние содержит всю видимую область, поэтому событий видимости нет,
+	// а следующие события считаются от этого кадра
+	t.visible = frame
+}
+
+// buildWorldState строит полное состояние локации из кадра
+func (c *Client) buildWorldState(frame *deltaFrame) *WorldState {
+	return &WorldState{
+		PlayerID:   c.GetInfo().PlayerID,
+		Location:   frame.snapshot.Location,
+		Characters: frame.snapshot.Characters,
+		Creatures:  frame.snapshot.Creatures,
+		Objects:    frame.snapshot.Objects,
+		LayerHash:  frame.layerHash,
+		Chat:       c.Server.chat.get(frame.locationID),
+		ServerTime: frame.snapshot.ServerTime,
+	}
+}
+
+// handleAck обрабатывает подтверждение получения обновления
+func (c *Client) handleAck(payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		c.sendError("parse_error", "Ошибка парсинга запроса")
+		return
+	}
+
+	var req AckRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.sendError("invalid_request", "Неверный формат подтверждения")
+		return
+	}
+
+	// Неизвестный или устаревший Seq просто игнорируем
+	c.deltaState().ack(req.Seq)
+}
@@ -0,0 +1,61 @@
+package network
+
+import (
+	"LOIL-server/internal/metrics"
+)
+
+// serverMetrics - метрики сетевого сервера для /metrics
+type serverMetrics struct {
+	messagesIn         *metrics.CounterVec // Входящие сообщения по типам
+	messagesOut        *metrics.CounterVec // Исходящие сообщения по типам
+	bytesSent          *metrics.CounterVec // Байты, записанные в соединения
+	dropped            *metrics.CounterVec // Сообщения, не поместившиеся в канал отправки клиента
+	joinFailures       *metrics.CounterVec // Неудачные join по коду ошибки
+	connectionLifetime *metrics.Histogram  // Длительность WebSocket соединений
+}
+
+// clientMessageTypes - типы сообщений клиента; остальные учитываются как unknown,
+// чтобы клиент не мог создать произвольное число меток
+var clientMessageTypes = map[MessageType]bool{
+	MsgJoin:     true,
+	MsgResume:   true,
+	MsgMove:     true,
+	MsgStop:     true,
+	MsgInteract: true,
+	MsgAck:      true,
+	MsgChat:     true,
+	MsgEmote:    true,
+	MsgEat:      true,
+	MsgDrink:    true,
+	MsgAttack:   true,
+
+	MsgInventoryMove:  true,
+	MsgInventorySplit: true,
+	MsgInventoryDrop:  true,
+	MsgEquip:          true,
+	MsgUnequip:        true,
+	MsgPong:           true,
+}
+
+func newServerMetrics(registry *metrics.Registry) *serverMetrics {
+	m := &serverMetrics{
+		messagesIn:   metrics.NewCounterVec("loil_messages_received_total", "Сообщения, полученные от клиентов, по типу", "type"),
+		messagesOut:  metrics.NewCounterVec("loil_messages_sent_total", "Сообщения, поставленные в очередь отправки клиентам, по типу", "type"),
+		bytesSent:    metrics.NewCounterVec("loil_bytes_sent_total", "Байты, отправленные клиентам"),
+		dropped:      metrics.NewCounterVec("loil_messages_dropped_total", "Сообщения, отброшенные из-за переполнения канала отправки, по типу", "type"),
+		joinFailures: metrics.NewCounterVec("loil_join_failures_total", "Неудачные попытки присоединения по коду ошибки", "code"),
+		connectionLifetime: metrics.NewHistogram("loil_connection_lifetime_seconds", "Длительность WebSocket соединений",
+			[]float64{1, 10, 30, 60, 300, 900, 1800, 3600, 4 * 3600, 12 * 3600}),
+	}
+
+	registry.Register(m.messagesIn, m.messagesOut, m.bytesSent, m.dropped, m.joinFailures, m.connectionLifetime)
+	return m
+}
+
+// received учитывает входящее сообщение
+func (m *serverMetrics) received(msgType MessageType) {
+	if !clientMessageTypes[msgType] {
+		msgType = "unknown"
+	}
+	m.messagesIn.With(string(msgType)).Inc()
+}
@@ -0,0 +1,218 @@
+package network
+
+import (
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// RateLimit - ограничение частоты сообщений одного типа (token bucket).
+// Клиент может отправить Burst сообщений подряд, дальше - не чаще Rate в секунду.
+type RateLimit struct {
+	Rate  float64 // Пополнение корзины, сообщений в секунду
+	Burst int     // Емкость корзины
+}
+
+// DefaultRateLimits - ограничения по умолчанию для сообщений клиента
+func DefaultRateLimits() map[MessageType]RateLimit {
+	return map[MessageType]RateLimit{
+		MsgJoin:     {Rate: 1, Burst: 3},
+		MsgResume:   {Rate: 1, Burst: 3},
+		MsgMove:     {Rate: 10, Burst: 20},
+		MsgStop:     {Rate: 10, Burst: 20},
+		MsgInteract: {Rate: 5, Burst: 5},
+		MsgChat:     {Rate: 1, Burst: 5},
+		MsgEmote:    {Rate: 1, Burst: 3},
+		MsgEat:      {Rate: 2, Burst: 5},
+		MsgDrink:    {Rate: 2, Burst: 5},
+		MsgAttack:   {Rate: 2, Burst: 4},
+
+		MsgInventoryMove:  {Rate: 5, Burst: 10},
+		MsgInventorySplit: {Rate: 5, Burst: 10},
+		MsgInventoryDrop:  {Rate: 5, Burst: 10},
+		MsgEquip:          {Rate: 5, Burst: 10},
+		MsgUnequip:        {Rate: 5, Burst: 10},
+		MsgAck:            {Rate: 30, Burst: 60},
+		MsgPong:           {Rate: 2, Burst: 5},
+	}
+}
+
+// tokenBucket - корзина токенов одного типа сообщений
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow забирает токен из корзины, если он есть
+func (b *tokenBucket) allow(now time.Time, limit RateLimit) bool {
+	if b.last.IsZero() {
+		b.tokens = float64(limit.Burst)
+	} else {
+		b.tokens = min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter - ограничения входящих сообщений клиента.
+// Используется только из readPump, поэтому не требует блокировок.
+type rateLimiter struct {
+	config     *ServerConfig
+	buckets    map[MessageType]*tokenBucket
+	violations []time.Time // Время недавних нарушений
+}
+
+func newRateLimiter(config *ServerConfig) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		buckets: make(map[MessageType]*tokenBucket),
+	}
+}
+
+// allow проверяет, можно ли обработать сообщение типа msgType
+func (l *rateLimiter) allow(msgType MessageType, now time.Time) bool {
+	limit, ok := l.config.RateLimits[msgType]
+	if !ok {
+		// Все остальные типы (в том числе неизвестные) делят общую корзину
+		msgType = ""
+		limit = l.config.DefaultRateLimit
+	}
+	if limit.Rate <= 0 {
+		return true
+	}
+
+	bucket := l.buckets[msgType]
+	if bucket == nil {
+		bucket = &tokenBucket{}
+		l.buckets[msgType] = bucket
+	}
+	return bucket.allow(now, limit)
+}
+
+// violation регистрирует нарушение и сообщает, превышен ли допустимый предел
+func (l *rateLimiter) violation(now time.Time) bool {
+	// Забываем нарушения вне окна
+	cutoff := now.Add(-l.config.ViolationWindow)
+	kept := l.violations[:0]
+	for _, at := range l.violations {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	l.violations = append(kept, now)
+
+	return l.config.MaxViolations > 0 && len(l.violations) >= l.config.MaxViolations
+}
+
+// floodStats - счетчики защиты от флуда для /health
+type floodStats struct {
+	rateLimited int64 // Отклоненные сообщения
+	kicked      int64 // Отключенные клиенты
+	rejected    int64 // Отклоненные подключения с забаненных адресов
+}
+
+// banList - временные баны по IP адресу
+type banList struct {
+	mu   sync.Mutex
+	bans map[string]time.Time // IP -> окончание бана
+}
+
+// ban запрещает подключения с адреса ip до until
+func (b *banList) ban(ip string, until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.bans == nil {
+		b.bans = make(map[string]time.Time)
+	}
+	b.bans[ip] = until
+}
+
+// banned проверяет, забанен ли адрес
+func (b *banList) banned(ip string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until, ok := b.bans[ip]
+	if ok && !now.Before(until) {
+		delete(b.bans, ip)
+		return false
+	}
+	return ok
+}
+
+// active возвращает количество действующих банов
+func (b *banList) active(now time.Time) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ip, until := range b.bans {
+		if !now.Before(until) {
+			delete(b.bans, ip)
+		}
+	}
+	return len(b.bans)
+}
+
+// checkRate проверяет лимит сообщения клиента. При превышении клиент получает
+// ошибку rate_limited, а после MaxViolations нарушений отключается и его адрес банится.
+// Возвращает false, если сообщение нужно отбросить.
+func (c *Client) checkRate(msgType MessageType) bool {
+	now := time.Now()
+	if c.limiter.allow(msgType, now) {
+		return true
+	}
+
+	atomic.AddInt64(&c.Server.flood.rateLimited, 1)
+
+	if c.limiter.violation(now) {
+		c.kick("Превышен лимит сообщений")
+		return false
+	}
+
+	c.sendMessage(Message{
+		Type: MsgError,
+		Payload: ErrorMessage{
+			Code:    "rate_limited",
+			Message: "Слишком много сообщений, подождите",
+			Details: string(msgType),
+		},
+		Time: Now(),
+		Seq:  c.getNextSeq(),
+	})
+	return false
+}
+
+// kick отключает клиента за флуд и временно банит его адрес
+func (c *Client) kick(reason string) {
+	config := c.Server.Config
+	ip := clientIP(c.Info.IP)
+
+	atomic.AddInt64(&c.Server.flood.kicked, 1)
+	if config.BanDuration > 0 {
+		c.Server.bans.ban(ip, time.Now().Add(config.BanDuration))
+	}
+	log.Printf("Клиент %s (%s) отключен: %s, бан на %v", c.Info.ID, ip, reason, config.BanDuration)
+
+	// Персонаж не ждет переподключения нарушителя
+	c.leaving.Store(true)
+	c.Close(websocket.ClosePolicyViolation, reason)
+}
+
+// clientIP возвращает IP адрес без порта
+func clientIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
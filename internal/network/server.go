@@ -1,11 +1,15 @@
 package network
 
 import (
+	"LOIL-server/internal/metrics"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,6 +21,7 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // В продакшене ограничить
 	},
+	Subprotocols: Subprotocols(),
 }
 
 // ClientInfo - информация о клиенте
@@ -33,7 +38,7 @@ type ClientInfo struct {
 // Server - WebSocket сервер
 type Server struct {
 	Clients      map[string]*Client
-	Broadcast    chan []byte
+	Broadcast    chan Message
 	Register     chan *Client
 	Unregister   chan *Client
 	Game         GameStateProvider
@@ -41,16 +46,44 @@ type Server struct {
 	mu           sync.RWMutex
 	Sequence     int64
 	Config       *ServerConfig
+	Auth         Authenticator     // Проверка токенов при join (nil - авторизация отключена)
+	Accounts     *AccountStore     // Учетные записи для /register и /login
+	ChatFilters  []ChatFilter      // Фильтры сообщений чата (применяются по порядку)
+	Metrics      *metrics.Registry // Метрики для /metrics (игра может добавить свои)
+
+	sessions     map[string]*Session // Сессии игроков по ID (защищены mu)
+	bans         banList             // Временные баны адресов за флуд
+	flood        floodStats          // Счетчики защиты от флуда
+	chat         chatHistory         // История чата по локациям
+	metrics      *serverMetrics      // Счетчики сообщений и соединений
+	httpServer   *http.Server
+	done         chan struct{} // Закрывается при остановке сервера
+	shutdownOnce sync.Once
+	loops        sync.WaitGroup // Фоновые горутины сервера (регистрация, рассылка, пинги)
+	pumps        sync.WaitGroup // Горутины чтения и записи клиентов
 }
 
+// DefaultShutdownReason - причина остановки, которую получают клиенты по умолчанию
+const DefaultShutdownReason = "Сервер останавливается"
+
 // Client - клиентское соединение (определение здесь, реализация в client.go)
 type Client struct {
-	Info     *ClientInfo
-	Conn     *websocket.Conn
-	Send     chan []byte
-	Server   *Server
-	mu       sync.Mutex
-	sequence int64
+	Info      *ClientInfo
+	Conn      *websocket.Conn
+	Send      chan []byte
+	Server    *Server
+	mu        sync.Mutex
+	infoMu    sync.RWMutex // Защищает Info при чтении из других горутин
+	sendMu    sync.Mutex   // Защищает Send от записи после закрытия
+	closed    bool
+	closeCode int    // Код close frame, отправляемого при закрытии
+	closeText string // Причина закрытия для close frame
+	sequence  int64
+	delta     *deltaTracker           // Базовое состояние для дельта-обновлений до создания сессии (см. deltaState)
+	limiter   *rateLimiter            // Ограничение частоты входящих сообщений
+	codec     Codec                   // Кодирование сообщений, выбранное подпротоколом
+	session   atomic.Pointer[Session] // Сессия игрока после join или resume
+	leaving   atomic.Bool             // Клиент закрыл соединение сам, ждать переподключения не нужно
 }
 
 // ServerConfig - конфигурация сервера
@@ -61,6 +94,18 @@ type ServerConfig struct {
 	MaxMessageSize int64
 	WriteTimeout   time.Duration
 	ReadTimeout    time.Duration
+
+	ViewRadius         float64       // Радиус видимости персонажа в клетках слоев (0 - вся локация)
+	ChatMaxLength      int           // Максимальная длина сообщения чата в символах (0 - без ограничения)
+	ChatHistorySize    int           // Сколько сообщений чата локации хранится для world_state
+	SessionGracePeriod time.Duration // Сколько персонаж ждет переподключения (0 - выход сразу)
+	ResumeBufferSize   int           // Сколько последних сообщений хранится для возобновления сессии
+
+	RateLimits       map[MessageType]RateLimit // Ограничения частоты по типам сообщений
+	DefaultRateLimit RateLimit                 // Ограничение для остальных типов (Rate 0 - без ограничения)
+	MaxViolations    int                       // Сколько нарушений за ViolationWindow приводит к отключению (0 - не отключать)
+	ViolationWindow  time.Duration
+	BanDuration      time.Duration // На сколько банится адрес отключенного нарушителя (0 - без бана)
 }
 
 // DefaultConfig - конфигурация по умолчанию
@@ -72,6 +117,18 @@ func DefaultConfig() *ServerConfig {
 		MaxMessageSize: 1024 * 10,
 		WriteTimeout:   10 * time.Second,
 		ReadTimeout:    60 * time.Second,
+
+		ViewRadius:         15,
+		ChatMaxLength:      200,
+		ChatHistorySize:    50,
+		SessionGracePeriod: 60 * time.Second,
+		ResumeBufferSize:   256,
+
+		RateLimits:       DefaultRateLimits(),
+		DefaultRateLimit: RateLimit{Rate: 10, Burst: 20},
+		MaxViolations:    20,
+		ViolationWindow:  10 * time.Second,
+		BanDuration:      5 * time.Minute,
 	}
 }
 
@@ -81,35 +138,139 @@ func NewServer(game GameStateProvider, config *ServerConfig) *Server {
 		config = DefaultConfig()
 	}
 
-	return &Server{
+	registry := metrics.NewRegistry()
+
+	s := &Server{
 		Clients:      make(map[string]*Client),
-		Broadcast:    make(chan []byte, 100),
+		Broadcast:    make(chan Message, 100),
 		Register:     make(chan *Client),
 		Unregister:   make(chan *Client),
 		Game:         game,
 		UpdateTicker: time.NewTicker(config.UpdateInterval),
 		Config:       config,
+		sessions:     make(map[string]*Session),
+		httpServer:   &http.Server{Addr: config.Addr},
+		done:         make(chan struct{}),
+		Metrics:      registry,
+		metrics:      newServerMetrics(registry),
 	}
+
+	registry.Register(metrics.NewGaugeFunc("loil_clients", "Подключенные клиенты и сессии", []string{"state"},
+		func(emit func(value float64, labelValues ...string)) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			emit(float64(len(s.Clients)), "connected")
+			emit(float64(len(s.sessions)), "sessions")
+		}))
+	return s
 }
 
-// Start запускает сервер
+// Start запускает сервер и блокируется до его остановки через Shutdown
 func (s *Server) Start() error {
 	// Запускаем обработчики
+	s.loops.Add(3)
 	go s.handleMessages()
 	go s.broadcastUpdates()
 	go s.sendPings()
 
-	http.HandleFunc("/ws", s.serveWebSocket)
-	http.HandleFunc("/health", s.healthCheck)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", s.serveWebSocket)
+	mux.HandleFunc("/health", s.healthCheck)
+	mux.Handle("/metrics", s.Metrics)
+	if s.Accounts != nil && s.Auth != nil {
+		mux.HandleFunc("/register", s.handleRegister)
+		mux.HandleFunc("/login", s.handleLogin)
+	}
+	s.httpServer.Addr = s.Config.Addr
+	s.httpServer.Handler = mux
 
 	log.Printf("Сервер запущен на %s", s.Config.Addr)
 	log.Printf("Интервал обновлений: %v", s.Config.UpdateInterval)
 
-	return http.ListenAndServe(s.Config.Addr, nil)
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown останавливает сервер с причиной по умолчанию
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.ShutdownWithReason(ctx, DefaultShutdownReason)
+}
+
+// ShutdownWithReason останавливает сервер: перестает принимать соединения,
+// останавливает фоновые горутины, отправляет клиентам server_shutdown и закрывает
+// соединения с кодом 1001 (going away). Если ctx истекает раньше, чем клиенты
+// получат сообщения, соединения закрываются принудительно.
+func (s *Server) ShutdownWithReason(ctx context.Context, reason string) error {
+	var err error
+	s.shutdownOnce.Do(func() {
+		log.Printf("Остановка сервера: %s", reason)
+
+		// Перестаем принимать новые соединения
+		err = s.httpServer.Shutdown(ctx)
+
+		// Останавливаем регистрацию, рассылку обновлений и пинги
+		close(s.done)
+		s.UpdateTicker.Stop()
+		s.loops.Wait()
+		s.closeSessions()
+
+		s.mu.Lock()
+		clients := make([]*Client, 0, len(s.Clients))
+		for id, client := range s.Clients {
+			clients = append(clients, client)
+			delete(s.Clients, id)
+		}
+		s.mu.Unlock()
+
+		// Уведомляем клиентов и закрываем соединения после отправки уведомления
+		for _, client := range clients {
+			client.sendMessage(Message{
+				Type: MsgServerShutdown,
+				Payload: ShutdownMessage{
+					Reason:     reason,
+					ServerTime: Now(),
+				},
+				Time: Now(),
+				Seq:  client.getNextSeq(),
+			})
+			client.Close(websocket.CloseGoingAway, reason)
+		}
+
+		// Ждем завершения клиентских горутин
+		pumpsDone := make(chan struct{})
+		go func() {
+			s.pumps.Wait()
+			close(pumpsDone)
+		}()
+
+		select {
+		case <-pumpsDone:
+		case <-ctx.Done():
+			log.Printf("Клиенты не закрылись вовремя, закрываем соединения принудительно")
+			for _, client := range clients {
+				client.Conn.Close()
+			}
+			if err == nil {
+				err = ctx.Err()
+			}
+		}
+
+		log.Printf("Сервер остановлен")
+	})
+	return err
 }
 
 // serveWebSocket обрабатывает WebSocket соединения
 func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Адреса, отключенные за флуд, временно не принимаются
+	if s.bans.banned(clientIP(r.RemoteAddr), time.Now()) {
+		atomic.AddInt64(&s.flood.rejected, 1)
+		http.Error(w, "Адрес временно заблокирован", http.StatusForbidden)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Ошибка WebSocket: %v", err)
@@ -125,30 +286,47 @@ func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &Client{
-		Info:   clientInfo,
-		Conn:   conn,
-		Send:   make(chan []byte, 256),
-		Server: s,
+		Info:    clientInfo,
+		Conn:    conn,
+		Send:    make(chan []byte, 256),
+		Server:  s,
+		delta:   newDeltaTracker(),
+		limiter: newRateLimiter(s.Config),
+		codec:   CodecFor(conn.Subprotocol()),
 	}
 
-	s.Register <- client
+	if !s.register(client) {
+		// Сервер останавливается
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, DefaultShutdownReason))
+		conn.Close()
+		return
+	}
 
 	// Запускаем обработчики клиента
+	s.pumps.Add(2)
 	go client.writePump()
 	go client.readPump()
 
-	log.Printf("Клиент подключен: %s (%s)", clientID, r.RemoteAddr)
+	log.Printf("Клиент подключен: %s (%s, %s)", clientID, r.RemoteAddr, client.codec.Subprotocol())
 }
 
 // healthCheck - проверка здоровья сервера
 func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
 	clientCount := len(s.Clients)
+	sessionCount := len(s.sessions)
 	s.mu.RUnlock()
 
 	response := map[string]interface{}{
-		"status":      "ok",
-		"clients":     clientCount,
+		"status":   "ok",
+		"clients":  clientCount,
+		"sessions": sessionCount,
+		"flood": map[string]interface{}{
+			"rate_limited":         atomic.LoadInt64(&s.flood.rateLimited),
+			"kicked":               atomic.LoadInt64(&s.flood.kicked),
+			"rejected_connections": atomic.LoadInt64(&s.flood.rejected),
+			"banned_ips":           s.bans.active(time.Now()),
+		},
 		"server_time": Now(),
 		"update_rate": s.Config.UpdateInterval.String(),
 	}
@@ -157,10 +335,36 @@ func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// register передает клиента обработчику регистрации.
+// Возвращает false, если сервер уже останавливается.
+func (s *Server) register(client *Client) bool {
+	select {
+	case s.Register <- client:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// unregister передает отключившегося клиента обработчику регистрации
+func (s *Server) unregister(client *Client) {
+	select {
+	case s.Unregister <- client:
+	case <-s.done:
+		// Сервер останавливается - клиентов закрывает Shutdown
+		client.closeSend()
+	}
+}
+
 // handleMessages обрабатывает системные сообщения
 func (s *Server) handleMessages() {
+	defer s.loops.Done()
+
 	for {
 		select {
+		case <-s.done:
+			return
+
 		case client := <-s.Register:
 			s.mu.Lock()
 			s.Clients[client.Info.ID] = client
@@ -168,23 +372,24 @@ func (s *Server) handleMessages() {
 
 		case client := <-s.Unregister:
 			s.mu.Lock()
-			if _, ok := s.Clients[client.Info.ID]; ok {
+			_, ok := s.Clients[client.Info.ID]
+			if ok {
 				delete(s.Clients, client.Info.ID)
-				close(client.Send)
+				client.closeSend()
+				s.metrics.connectionLifetime.Observe(time.Since(client.Info.ConnectedAt).Seconds())
 				log.Printf("Клиент отключен: %s", client.Info.ID)
 			}
 			s.mu.Unlock()
 
+			// Персонаж игрока ждет переподключения или освобождается
+			if ok {
+				s.detachClient(client)
+			}
+
 		case message := <-s.Broadcast:
 			s.mu.RLock()
 			for _, client := range s.Clients {
-				select {
-				case client.Send <- message:
-				default:
-					log.Printf("Канал клиента %s переполнен", client.Info.ID)
-					close(client.Send)
-					delete(s.Clients, client.Info.ID)
-				}
+				client.sendMessage(message)
 			}
 			s.mu.RUnlock()
 		}
@@ -193,95 +398,153 @@ func (s *Server) handleMessages() {
 
 // broadcastUpdates рассылает обновления состояния
 func (s *Server) broadcastUpdates() {
-	for range s.UpdateTicker.C {
-		s.mu.RLock()
+	defer s.loops.Done()
 
-		// Группируем клиентов по локациям
-		clientsByLocation := make(map[int][]*Client)
-		for _, client := range s.Clients {
-			if client.Info.LocationID > 0 {
-				clientsByLocation[client.Info.LocationID] = append(
-					clientsByLocation[client.Info.LocationID], client)
-			}
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.UpdateTicker.C:
+			s.sendLocationUpdates()
 		}
+	}
+}
 
-		s.mu.RUnlock()
+// sendLocationUpdates рассылает клиентам изменения в области видимости их персонажей
+func (s *Server) sendLocationUpdates() {
+	s.mu.RLock()
+	clients := make([]*Client, 0, len(s.Clients))
+	for _, client := range s.Clients {
+		clients = append(clients, client)
+	}
+	s.mu.RUnlock()
 
-		// Рассылаем обновления для каждой локации
-		for locationID, clients := range clientsByLocation {
-			if len(clients) > 0 {
-				update := s.createLocationUpdate(locationID)
-				if update != nil {
-					msg := Message{
-						Type:    MsgLocationUpdate,
-						Payload: update,
-						Time:    Now(),
-					}
-
-					data, err := json.Marshal(msg)
-					if err != nil {
-						log.Printf("Ошибка маршалинга: %v", err)
-						continue
-					}
-
-					// Отправляем всем клиентам в локации
-					for _, client := range clients {
-						client.sendRaw(data)
-					}
-				}
-			}
+	// Группируем клиентов по текущим локациям их персонажей
+	type viewer struct {
+		client *Client
+		x      float64
+	}
+	viewersByLocation := make(map[int][]viewer)
+	for _, client := range clients {
+		info := client.GetInfo()
+		if info.CharacterID == 0 || info.LocationID == 0 {
+			continue
+		}
+
+		locationID, x, ok := s.Game.GetCharacterPosition(info.CharacterID)
+		if !ok {
+			continue
+		}
+		if locationID != info.LocationID {
+			client.setLocation(locationID)
+		}
+		viewersByLocation[locationID] = append(viewersByLocation[locationID], viewer{client: client, x: x})
+	}
+
+	// Кадр локации строится один раз, каждый клиент получает только видимую часть
+	for locationID, viewers := range viewersByLocation {
+		frame := s.createLocationFrame(locationID)
+		if frame == nil {
+			continue
+		}
+
+		for _, v := range viewers {
+			v.client.sendLocationUpdate(frame.view(v.x, s.Config.ViewRadius))
 		}
 	}
 }
 
-// createLocationUpdate создает обновление локации
-func (s *Server) createLocationUpdate(locationID int) *LocationUpdate {
-	locationState := s.Game.GetLocationState(locationID)
-	if locationState == nil {
+// createLocationFrame создает кадр состояния локации для рассылки
+func (s *Server) createLocationFrame(locationID int) *deltaFrame {
+	snapshot := s.Game.GetLocationSnapshot(locationID)
+	if snapshot == nil || snapshot.Location == nil {
 		return nil
 	}
 
-	return &LocationUpdate{
-		LocationID: locationID,
-		Characters: s.Game.GetCharactersInLocation(locationID),
-		Creatures:  s.Game.GetCreaturesInLocation(locationID),
-		Objects:    s.Game.GetObjectsInLocation(locationID),
-		ServerTime: s.Game.GetServerTime(),
-	}
+	return newDeltaFrame(locationID, snapshot)
 }
 
 // sendPings отправляет ping сообщения
 func (s *Server) sendPings() {
+	defer s.loops.Done()
+
 	ticker := time.NewTicker(s.Config.PingInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
 		msg := Message{
 			Type: MsgPing,
 			Time: Now(),
 		}
 
-		data, err := json.Marshal(msg)
-		if err != nil {
-			continue
-		}
-
 		s.mu.RLock()
 		for _, client := range s.Clients {
-			client.sendRaw(data)
+			client.sendMessage(msg)
 		}
 		s.mu.RUnlock()
 	}
 }
 
-// sendRawToClient отправляет данные клиенту (вызов метода клиента)
-func (s *Server) sendRawToClient(clientID string, data []byte) {
+// SendToPlayer отправляет сообщение всем соединениям игрока.
+// Если игрок временно отключен, сообщение ждет в буфере его сессии.
+func (s *Server) SendToPlayer(playerID int, msgType MessageType, payload interface{}) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, session := range s.sessions {
+		if session.PlayerID == playerID && session.detached() {
+			session.queue(msgType, payload)
+		}
+	}
+
+	for _, client := range s.Clients {
+		if client.GetInfo().PlayerID == playerID {
+			client.sendMessage(Message{
+				Type:    msgType,
+				Payload: payload,
+				Time:    Now(),
+				Seq:     client.getNextSeq(),
+			})
+		}
+	}
+}
+
+// SendToLocation отправляет сообщение всем присоединившимся клиентам, персонажи которых в локации
+func (s *Server) SendToLocation(locationID int, msgType MessageType, payload interface{}) {
+	s.sendToJoined(func(info ClientInfo) bool { return info.LocationID == locationID }, msgType, payload)
+}
+
+// sendToJoined отправляет сообщение присоединившимся клиентам, подходящим под условие
+func (s *Server) sendToJoined(match func(info ClientInfo) bool, msgType MessageType, payload interface{}) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, client := range s.Clients {
+		if info := client.GetInfo(); info.PlayerID != 0 && match(info) {
+			client.sendMessage(Message{
+				Type:    msgType,
+				Payload: payload,
+				Time:    Now(),
+				Seq:     client.getNextSeq(),
+			})
+		}
+	}
+}
+
+// sendToClient отправляет сообщение клиенту по ID соединения
+func (s *Server) sendToClient(clientID string, msg Message) {
 	s.mu.RLock()
 	client, ok := s.Clients[clientID]
 	s.mu.RUnlock()
 
 	if ok {
-		client.sendRaw(data)
+		client.sendMessage(msg)
 	}
 }
 
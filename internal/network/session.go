@@ -0,0 +1,339 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CloseSessionResumed - код закрытия старого соединения, сессию которого продолжило новое
+const CloseSessionResumed = 4000
+
+// Session - игровая сессия игрока, переживающая обрыв соединения.
+// Сессии принадлежат нумерация сообщений (Seq), буфер последних отправленных
+// сообщений и базовое состояние дельт, поэтому переподключившийся клиент
+// продолжает поток с того места, где он прервался.
+type Session struct {
+	ID          string
+	PlayerID    int
+	CharacterID int
+
+	sequence int64         // Последний выданный Seq (атомарно)
+	delta    *deltaTracker // Базовое состояние дельта-обновлений клиента
+
+	mu         sync.Mutex
+	client     *Client     // Текущее соединение (nil, пока клиент отключен)
+	expires    *time.Timer // Таймер окончания ожидания переподключения
+	buffer     []Message   // Последние отправленные сообщения (кодируются при повторе кодеком нового соединения)
+	bufferSize int
+	closed     bool
+	replaying  bool // Новое соединение еще не получило пропущенные сообщения
+}
+
+// nextSeq выдает следующий номер сообщения сессии
+func (s *Session) nextSeq() int64 {
+	return atomic.AddInt64(&s.sequence, 1)
+}
+
+// record запоминает сообщение, отправляемое клиенту client.
+// Возвращает false, если сообщение не нужно отправлять сейчас: сессию уже продолжило
+// другое соединение или клиенту еще повторяются пропущенные сообщения (тогда
+// сообщение уйдет вместе с ними из буфера).
+func (s *Session) record(client *Client, msg Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != client {
+		return false
+	}
+	s.appendLocked(msg)
+	return !s.replaying
+}
+
+func (s *Session) appendLocked(msg Message) {
+	s.buffer = append(s.buffer, msg)
+	if over := len(s.buffer) - s.bufferSize; over > 0 {
+		s.buffer = append(s.buffer[:0], s.buffer[over:]...)
+	}
+}
+
+// queue сохраняет сообщение для отключенного клиента, чтобы отправить его при возобновлении
+func (s *Session) queue(msgType MessageType, payload interface{}) {
+	msg := Message{
+		Type:    msgType,
+		Payload: payload,
+		Time:    Now(),
+		Seq:     s.nextSeq(),
+	}
+
+	s.mu.Lock()
+	s.appendLocked(msg)
+	s.mu.Unlock()
+}
+
+// replay отправляет соединению client сообщения с Seq больше lastSeq и возобновляет
+// живую доставку. До вызова notify и повтора новые сообщения сессии только попадают
+// в буфер, поэтому клиент получает их строго после пропущенных и в порядке Seq.
+// notify вызывается перед повтором с числом повторяемых сообщений.
+// ok == false, если часть пропущенных сообщений уже вытеснена из буфера - тогда ничего не повторяется.
+func (s *Session) replay(client *Client, lastSeq int64, notify func(replayed int, ok bool)) (replayed int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != client {
+		return 0, false
+	}
+
+	missed, ok := s.missedSinceLocked(lastSeq)
+	notify(len(missed), ok)
+	for _, msg := range missed {
+		client.writeMessage(msg)
+	}
+	s.replaying = false
+	return len(missed), ok
+}
+
+// missedSinceLocked возвращает сообщения с Seq больше lastSeq в порядке отправки.
+// ok == false, если часть пропущенных сообщений уже вытеснена из буфера.
+func (s *Session) missedSinceLocked(lastSeq int64) (messages []Message, ok bool) {
+	if lastSeq >= atomic.LoadInt64(&s.sequence) {
+		return nil, true
+	}
+
+	for _, msg := range s.buffer {
+		if msg.Seq > lastSeq {
+			messages = append(messages, msg)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Seq < messages[j].Seq })
+
+	// Сообщение сразу после lastSeq должно быть в буфере, иначе есть пропуск
+	if len(messages) == 0 || messages[0].Seq > lastSeq+1 {
+		return nil, false
+	}
+	return messages, true
+}
+
+// owns проверяет, что сессия принадлежит соединению client
+func (s *Session) owns(client *Client) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client == client
+}
+
+// detach отвязывает соединение от сессии и запускает ожидание переподключения
+func (s *Session) detach(client *Client, grace time.Duration, onExpire func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.client != client {
+		return false
+	}
+	s.client = nil
+	s.expires = time.AfterFunc(grace, onExpire)
+	return true
+}
+
+// attach привязывает к сессии новое соединение и возвращает предыдущее (если оно еще открыто).
+// Живые сообщения новому соединению придерживаются до вызова replay.
+func (s *Session) attach(client *Client) (*Client, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, false
+	}
+	if s.expires != nil {
+		s.expires.Stop()
+		s.expires = nil
+	}
+	previous := s.client
+	s.client = client
+	s.replaying = true
+	return previous, true
+}
+
+// close завершает сессию. Возвращает false, если она уже была завершена
+// или (при onlyDetached) к ней снова подключился клиент.
+func (s *Session) close(onlyDetached bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || (onlyDetached && s.client != nil) {
+		return false
+	}
+	s.closed = true
+	s.client = nil
+	if s.expires != nil {
+		s.expires.Stop()
+		s.expires = nil
+	}
+	return true
+}
+
+// detached проверяет, что сессия ждет переподключения клиента
+func (s *Session) detached() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.closed && s.client == nil
+}
+
+// startSession создает сессию для присоединившегося клиента.
+// Нумерация сообщений и базовое состояние дельт клиента переходят к сессии.
+func (s *Server) startSession(client *Client, playerID, characterID int) (*Session, error) {
+	id, err := generateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		ID:          id,
+		PlayerID:    playerID,
+		CharacterID: characterID,
+		sequence:    atomic.LoadInt64(&client.sequence),
+		delta:       client.delta,
+		client:      client,
+		bufferSize:  s.Config.ResumeBufferSize,
+	}
+
+	s.mu.Lock()
+	s.sessions[id] = session
+	s.mu.Unlock()
+
+	client.session.Store(session)
+	return session, nil
+}
+
+// resumeSession привязывает клиента к сессии с указанным ID и передает ему игрока
+// и персонажа сессии. Предыдущее соединение сессии, если оно еще открыто, закрывается.
+func (s *Server) resumeSession(id string, client *Client) (*Session, error) {
+	s.mu.Lock()
+	session, ok := s.sessions[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, NewError("session_not_found", "Сессия не найдена или истекла")
+	}
+	// Привязываем и назначаем игрока под блокировкой сервера, чтобы SendToPlayer видел
+	// либо отключенную сессию, либо уже новое соединение этого игрока
+	previous, ok := session.attach(client)
+	if ok {
+		// Локация берется из снимка: ожидание игрового цикла под s.mu заблокировало бы
+		// цикл, рассылающий события через SendToLocation
+		locationID, _, _ := s.Game.GetCharacterPosition(session.CharacterID)
+
+		client.session.Store(session)
+		client.infoMu.Lock()
+		client.Info.PlayerID = session.PlayerID
+		client.Info.CharacterID = session.CharacterID
+		client.Info.LocationID = locationID
+		client.infoMu.Unlock()
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, NewError("session_not_found", "Сессия не найдена или истекла")
+	}
+
+	if previous != nil && previous != client {
+		previous.Close(CloseSessionResumed, "Сессия продолжена в другом соединении")
+	}
+	return session, nil
+}
+
+// takeOverDetachedSession завершает отключенную сессию игрока, чтобы он мог
+// присоединиться заново через join вместо resume
+func (s *Server) takeOverDetachedSession(playerID int) {
+	s.mu.Lock()
+	var ended *Session
+	for id, session := range s.sessions {
+		if session.PlayerID == playerID && session.detached() && session.close(true) {
+			delete(s.sessions, id)
+			ended = session
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if ended != nil {
+		log.Printf("Сессия %s игрока %d заменена новым присоединением", ended.ID, playerID)
+		s.Game.HandleLeave(playerID)
+	}
+}
+
+// detachClient обрабатывает отключение присоединившегося клиента: персонаж
+// останавливается и ждет переподключения, если клиент отключился не сам.
+func (s *Server) detachClient(client *Client) {
+	info := client.GetInfo()
+	if info.PlayerID == 0 {
+		return
+	}
+
+	session := client.session.Load()
+	if session != nil && !session.owns(client) {
+		// Сессию уже продолжило другое соединение
+		return
+	}
+
+	grace := s.Config.SessionGracePeriod
+	if session == nil || grace <= 0 || client.leaving.Load() {
+		if session != nil {
+			s.endSession(session)
+		}
+		s.Game.HandleLeave(info.PlayerID)
+		return
+	}
+
+	if !session.detach(client, grace, func() { s.expireSession(session) }) {
+		return
+	}
+
+	s.Game.HandleDisconnect(info.PlayerID)
+	log.Printf("Сессия %s игрока %d ждет переподключения %v", session.ID, info.PlayerID, grace)
+}
+
+// expireSession завершает сессию, к которой клиент не вернулся вовремя
+func (s *Server) expireSession(session *Session) {
+	if !session.close(true) {
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.sessions, session.ID)
+	s.mu.Unlock()
+
+	log.Printf("Сессия %s истекла, игрок %d покинул игру", session.ID, session.PlayerID)
+	s.Game.HandleLeave(session.PlayerID)
+}
+
+// endSession завершает сессию без ожидания переподключения
+func (s *Server) endSession(session *Session) {
+	session.close(false)
+
+	s.mu.Lock()
+	delete(s.sessions, session.ID)
+	s.mu.Unlock()
+}
+
+// closeSessions завершает все сессии (при остановке сервера)
+func (s *Server) closeSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, session := range s.sessions {
+		session.close(false)
+		delete(s.sessions, id)
+	}
+}
+
+// generateSessionID создает случайный ID сессии
+func generateSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,155 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loopGame - игра, которая, как игровой цикл, между командами непрерывно рассылает события.
+// Блокирующие методы ждут цикла (как Game.Do), снимковые отвечают сразу.
+type loopGame struct {
+	GameStateProvider
+
+	server   *Server
+	commands chan func()
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+func newLoopGame() *loopGame {
+	return &loopGame{
+		commands: make(chan func()),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// run выполняет команды и рассылает событие в локацию 1 на каждом тике
+func (g *loopGame) run() {
+	defer close(g.stopped)
+	for {
+		select {
+		case <-g.stop:
+			return
+		case cmd := <-g.commands:
+			cmd()
+		default:
+		}
+		g.server.SendToLocation(1, MsgCombat, &CombatUpdate{LocationID: 1, Damage: 1})
+	}
+}
+
+// do выполняет функцию в цикле и ждет ее завершения
+func (g *loopGame) do(f func()) {
+	done := make(chan struct{})
+	select {
+	case g.commands <- func() { f(); close(done) }:
+		<-done
+	case <-g.stopped:
+	}
+}
+
+func (g *loopGame) GetCharacterByID(characterID int) *CharacterState {
+	var state *CharacterState
+	g.do(func() { state = &CharacterState{ID: characterID, LocationID: 1, X: 5} })
+	return state
+}
+
+func (g *loopGame) GetCharacterPosition(characterID int) (int, float64, bool) {
+	return 1, 5, true
+}
+
+// newTestClient создает присоединенного клиента без сетевого соединения.
+// Отправленные ему сообщения вычитываются и отбрасываются.
+func newTestClient(t *testing.T, s *Server, id string, playerID int) *Client {
+	t.Helper()
+
+	client := &Client{
+		Info:    &ClientInfo{ID: id, PlayerID: playerID, CharacterID: playerID, LocationID: 1},
+		Send:    make(chan []byte, 256),
+		Server:  s,
+		delta:   newDeltaTracker(),
+		limiter: newRateLimiter(s.Config),
+		codec:   JSONCodec{},
+	}
+	go func() {
+		for range client.Send {
+		}
+	}()
+	t.Cleanup(func() { client.Close(CloseSessionResumed, "") })
+
+	s.mu.Lock()
+	s.Clients[id] = client
+	s.mu.Unlock()
+	return client
+}
+
+func TestResumeWhileLoopBroadcasts(t *testing.T) {
+	const resumes = 50
+
+	game := newLoopGame()
+	s := NewServer(game, DefaultConfig())
+	defer s.UpdateTicker.Stop()
+	game.server = s
+
+	newTestClient(t, s, "watcher", 1000)
+
+	// Сессии отключившихся игроков, которые будут продолжены
+	sessions := make([]*Session, resumes)
+	for i := range sessions {
+		playerID := i + 1
+		old := newTestClient(t, s, fmt.Sprintf("old_%d", playerID), playerID)
+		session, err := s.startSession(old, playerID, playerID)
+		if err != nil {
+			t.Fatalf("startSession: %v", err)
+		}
+		session.detach(old, time.Minute, func() {})
+		s.mu.Lock()
+		delete(s.Clients, old.Info.ID)
+		s.mu.Unlock()
+		sessions[i] = session
+	}
+
+	go game.run()
+
+	clients := make([]*Client, resumes)
+	for i := range clients {
+		clients[i] = newTestClient(t, s, fmt.Sprintf("new_%d", i+1), 0)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var wg sync.WaitGroup
+		for i, session := range sessions {
+			wg.Add(1)
+			go func(client *Client, session *Session) {
+				defer wg.Done()
+				client.handleResume(ResumeRequest{SessionID: session.ID, LastSeq: 0})
+			}(clients[i], session)
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+		close(game.stop)
+		<-game.stopped
+	case <-time.After(5 * time.Second):
+		close(game.stop)
+		t.Fatal("возобновление сессий заблокировалось вместе с рассылкой игрового цикла")
+	}
+
+	for i, session := range sessions {
+		if !session.owns(clients[i]) {
+			t.Errorf("сессия %d не продолжена новым соединением", i+1)
+		}
+		info := clients[i].GetInfo()
+		if info.PlayerID != i+1 || info.LocationID != 1 {
+			t.Errorf("клиент new_%d: игрок %d, локация %d", i+1, info.PlayerID, info.LocationID)
+		}
+	}
+}
@@ -9,12 +9,25 @@ type GameStateProvider interface {
 	GetCreaturesInLocation(locationID int) []*CreatureState
 	GetObjectsInLocation(locationID int) []*ObjectState
 	GetCharacterByID(characterID int) *CharacterState
+	GetCharacterPosition(characterID int) (locationID int, x float64, ok bool) // Позиция из последнего снимка
+	GetLocationSnapshot(locationID int) *LocationSnapshot
 
 	// Обработка действий
 	HandleJoin(playerID, characterID, locationID int) (*CharacterState, error)
+	HandleLeave(playerID int)
+	HandleDisconnect(playerID int) // Соединение оборвалось, персонаж ждет возобновления сессии
 	HandleMove(playerID int, direction, vertical int) error
 	HandleStop(playerID int) error
 	HandleInteract(playerID int, objectID, interactionIdx int) (*InteractionResult, error)
+	HandleEat(playerID int, slot int) error
+	HandleDrink(playerID int) error
+	HandleAttack(playerID int, creatureID int) (*InteractionResult, error)
+	HandleInventoryMove(playerID int, from, to int) error
+	HandleInventorySplit(playerID int, from, to, count int) error
+	HandleInventoryDrop(playerID int, slot, count int) error
+	HandleEquip(playerID int, slot int) error
+	HandleUnequip(playerID int, toolKind string) error
+	GetInventory(characterID int) *InventoryUpdate
 
 	// Утилиты
 	GetServerTime() int64
@@ -32,6 +45,18 @@ const (
 	MsgInteractionResult MessageType = "interaction_result"
 	MsgError             MessageType = "error"
 	MsgPing              MessageType = "ping"
+	MsgActionStarted     MessageType = "action_started"
+	MsgActionProgress    MessageType = "action_progress"
+	MsgActionCompleted   MessageType = "action_completed"
+	MsgServerShutdown    MessageType = "server_shutdown"
+	MsgSession           MessageType = "session"
+	MsgSessionResumed    MessageType = "session_resumed"
+	MsgVisibility        MessageType = "visibility"
+	MsgCombat            MessageType = "combat"
+	MsgCreatureDied      MessageType = "creature_died"
+	MsgCharacterDied     MessageType = "character_died"
+	MsgInventoryUpdate   MessageType = "inventory_update"
+	MsgChat              MessageType = "chat" // Также от клиента к серверу
 
 	// От клиента к серверу
 	MsgJoin     MessageType = "join"
@@ -39,6 +64,18 @@ const (
 	MsgStop     MessageType = "stop"
 	MsgInteract MessageType = "interact"
 	MsgPong     MessageType = "pong"
+	MsgAck      MessageType = "ack"
+	MsgResume   MessageType = "resume"
+	MsgEmote    MessageType = "emote"
+	MsgEat      MessageType = "eat"
+	MsgDrink    MessageType = "drink"
+	MsgAttack   MessageType = "attack"
+
+	MsgInventoryMove  MessageType = "inventory_move"
+	MsgInventorySplit MessageType = "inventory_split"
+	MsgInventoryDrop  MessageType = "inventory_drop"
+	MsgEquip          MessageType = "equip"
+	MsgUnequip        MessageType = "unequip"
 )
 
 // Message - базовое сообщение
@@ -54,7 +91,25 @@ type JoinRequest struct {
 	PlayerID    int    `json:"player_id"`
 	CharacterID int    `json:"character_id,omitempty"`
 	LocationID  int    `json:"location_id"`
-	Token       string `json:"token,omitempty"` // Для будущей авторизации
+	Token       string `json:"token,omitempty"` // Токен, выданный /register или /login
+}
+
+// ResumeRequest - запрос на возобновление сессии после обрыва соединения
+type ResumeRequest struct {
+	SessionID string `json:"session_id"`
+	LastSeq   int64  `json:"last_seq"` // Последний полученный клиентом Seq
+}
+
+// ChatRequest - сообщение чата от клиента
+type ChatRequest struct {
+	Scope string `json:"scope"`        // local, global или whisper
+	Text  string `json:"text"`         // Текст сообщения
+	To    int    `json:"to,omitempty"` // ID игрока-получателя для whisper
+}
+
+// EmoteRequest - эмоция персонажа
+type EmoteRequest struct {
+	Emote string `json:"emote"` // wave, bow, laugh, cheer, dance, sit
 }
 
 // MoveRequest - запрос на движение
@@ -66,7 +121,51 @@ type MoveRequest struct {
 // InteractRequest - запрос на взаимодействие
 type InteractRequest struct {
 	ObjectID       int `json:"object_id"`
-	InteractionIdx int `json:"interaction_idx"`
+	InteractionIdx int `json:"interaction_idx"` // Номер среди доступных персонажу действий объекта (как в консольной команде act)
+}
+
+// AttackRequest - удар по существу рядом с персонажем
+type AttackRequest struct {
+	CreatureID int `json:"creature_id"`
+}
+
+// EatRequest - съесть предмет из слота инвентаря
+type EatRequest struct {
+	Slot int `json:"slot"`
+}
+
+// InventoryMoveRequest - переложить предмет в другой слот (одинаковые объединяются, разные меняются местами)
+type InventoryMoveRequest struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// InventorySplitRequest - отделить часть стопки в пустой слот
+type InventorySplitRequest struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+// InventoryDropRequest - выложить предметы на землю
+type InventoryDropRequest struct {
+	Slot  int `json:"slot"`
+	Count int `json:"count,omitempty"` // 0 - вся стопка
+}
+
+// EquipRequest - взять в руки инструмент из слота инвентаря
+type EquipRequest struct {
+	Slot int `json:"slot"`
+}
+
+// UnequipRequest - убрать инструмент в инвентарь
+type UnequipRequest struct {
+	ToolKind string `json:"tool_kind,omitempty"` // Пусто - все инструменты
+}
+
+// AckRequest - подтверждение получения world_state или location_update
+type AckRequest struct {
+	Seq int64 `json:"seq"`
 }
 
 // CharacterState - состояние персонажа для сети
@@ -77,7 +176,13 @@ type CharacterState struct {
 	X          float64 `json:"x"`
 	Direction  int     `json:"direction"`
 	Speed      float64 `json:"speed"`
+	Health     int     `json:"health"`
+	MaxHealth  int     `json:"max_health"`
+	Hunger     int     `json:"hunger"`  // 0 - сыт, 100 - истощен
+	Thirst     int     `json:"thirst"`  // 0 - напоен, 100 - обезвожен
+	Stamina    int     `json:"stamina"` // 0 - устал
 	Controlled int     `json:"controlled"`
+	Action     string  `json:"action,omitempty"` // Текущее длительное действие
 	LastUpdate int64   `json:"last_update"`
 }
 
@@ -119,6 +224,15 @@ type LocationState struct {
 	LastUpdate int64  `json:"last_update"`
 }
 
+// LocationSnapshot - согласованное состояние локации, собранное за один игровой тик
+type LocationSnapshot struct {
+	Location   *LocationState
+	Characters []*CharacterState
+	Creatures  []*CreatureState
+	Objects    []*ObjectState
+	ServerTime int64
+}
+
 // WorldState - полное состояние для клиента
 type WorldState struct {
 	PlayerID   int               `json:"player_id"`
@@ -126,17 +240,62 @@ type WorldState struct {
 	Characters []*CharacterState `json:"characters"`
 	Creatures  []*CreatureState  `json:"creatures"`
 	Objects    []*ObjectState    `json:"objects"`
+	LayerHash  string            `json:"layer_hash,omitempty"`
+	Chat       []*ChatMessage    `json:"chat,omitempty"` // Последние сообщения чата локации
 	ServerTime int64             `json:"server_time"`
 }
 
-// LocationUpdate - обновление локации
+// LocationUpdate - изменения локации относительно подтвержденного клиентом состояния (BaseSeq).
+// Characters, Creatures и Objects содержат только добавленные и измененные сущности.
 type LocationUpdate struct {
-	LocationID int               `json:"location_id"`
-	Characters []*CharacterState `json:"characters,omitempty"`
-	Creatures  []*CreatureState  `json:"creatures,omitempty"`
-	Objects    []*ObjectState    `json:"objects,omitempty"`
-	LayerHash  string            `json:"layer_hash,omitempty"` // Для будущих оптимизаций
-	ServerTime int64             `json:"server_time"`
+	LocationID        int               `json:"location_id"`
+	BaseSeq           int64             `json:"base_seq"`
+	Characters        []*CharacterState `json:"characters,omitempty"`
+	Creatures         []*CreatureState  `json:"creatures,omitempty"`
+	Objects           []*ObjectState    `json:"objects,omitempty"`
+	RemovedCharacters []int             `json:"removed_characters,omitempty"`
+	RemovedCreatures  []int             `json:"removed_creatures,omitempty"`
+	RemovedObjects    []int             `json:"removed_objects,omitempty"`
+	Layers            *LayerDiff        `json:"layers,omitempty"`
+	LayerHash         string            `json:"layer_hash,omitempty"` // Хеш слоев после применения изменений
+	ServerTime        int64             `json:"server_time"`
+}
+
+// LayerDiff - измененные клетки слоев. Применяется, только если хеш слоев клиента равен BaseHash
+type LayerDiff struct {
+	BaseHash   string       `json:"base_hash"`
+	Foreground []CellChange `json:"foreground,omitempty"`
+	Road       []CellChange `json:"road,omitempty"`
+	Ground     []CellChange `json:"ground,omitempty"`
+	Background []CellChange `json:"background,omitempty"`
+}
+
+// CellChange - новое значение клетки слоя
+type CellChange struct {
+	Index int `json:"i"`
+	Value int `json:"v"`
+}
+
+// Виды сущностей в событиях видимости и боя
+const (
+	EntityCharacter = "character"
+	EntityCreature  = "creature"
+	EntityObject    = "object"
+)
+
+// EntityRef - ссылка на сущность локации
+type EntityRef struct {
+	Kind string `json:"kind"` // character, creature или object
+	ID   int    `json:"id"`
+}
+
+// VisibilityUpdate - сущности, которые вошли в область видимости персонажа или покинули ее
+// (в том числе появились, исчезли или сменили локацию) с прошлого обновления
+type VisibilityUpdate struct {
+	LocationID int         `json:"location_id"`
+	Entered    []EntityRef `json:"entered,omitempty"`
+	Left       []EntityRef `json:"left,omitempty"`
+	ServerTime int64       `json:"server_time"`
 }
 
 // CharacterUpdate - обновление персонажа
@@ -149,11 +308,46 @@ type CharacterUpdate struct {
 
 // InteractionResult - результат взаимодействия
 type InteractionResult struct {
-	Success    bool            `json:"success"`
-	ObjectID   int             `json:"object_id,omitempty"`
-	Message    string          `json:"message,omitempty"`
-	Items      []InventoryItem `json:"items,omitempty"`
-	ServerTime int64           `json:"server_time"`
+	Success       bool            `json:"success"`
+	Code          string          `json:"code,omitempty"` // Код причины неудачи (tool_required, out_of_reach, ...)
+	ObjectID      int             `json:"object_id,omitempty"`
+	Interaction   string          `json:"interaction,omitempty"` // Тип взаимодействия
+	Message       string          `json:"message,omitempty"`
+	Items         []InventoryItem `json:"items,omitempty"`          // Реально полученные предметы
+	Object        *ObjectState    `json:"object,omitempty"`         // Состояние объекта после взаимодействия
+	TransformedTo int             `json:"transformed_to,omitempty"` // Новый тип объекта
+	Destroyed     bool            `json:"destroyed,omitempty"`      // Объект уничтожен
+	CreatureID    int             `json:"creature_id,omitempty"`    // Цель атаки
+	Creature      *CreatureState  `json:"creature,omitempty"`       // Состояние существа после удара
+	Damage        int             `json:"damage,omitempty"`         // Нанесенный урон
+	Killed        bool            `json:"killed,omitempty"`         // Существо погибло
+	ContainerID   int             `json:"container_id,omitempty"`   // Хранилище с добычей погибшего существа
+	ServerTime    int64           `json:"server_time"`
+}
+
+// InventoryUpdate - полное содержимое инвентаря персонажа
+type InventoryUpdate struct {
+	CharacterID int                      `json:"character_id"`
+	Size        int                      `json:"size"`       // Число слотов
+	Slots       map[int]InventoryItem    `json:"slots"`      // Номер слота -> предмет (пустые слоты отсутствуют)
+	Equipped    map[string]InventoryItem `json:"equipped"`   // Вид инструмента -> инструмент в руках
+	HandsFree   bool                     `json:"hands_free"` // Руки свободны
+	ServerTime  int64                    `json:"server_time"`
+}
+
+// ActionState - состояние длительного действия персонажа
+type ActionState struct {
+	CharacterID    int                `json:"character_id"`
+	Action         string             `json:"action"`
+	ObjectID       int                `json:"object_id"`
+	InteractionIdx int                `json:"interaction_idx"` // Индекс взаимодействия в конфиге объекта
+	Duration       float64            `json:"duration"`
+	Elapsed        float64            `json:"elapsed"`
+	Progress       float64            `json:"progress"` // 0-1
+	Cancelled      bool               `json:"cancelled,omitempty"`
+	Reason         string             `json:"reason,omitempty"` // Причина отмены
+	Result         *InteractionResult `json:"result,omitempty"` // Результат завершенного действия
+	ServerTime     int64              `json:"server_time"`
 }
 
 // InventoryItem - предмет инвентаря
@@ -170,6 +364,93 @@ type ErrorMessage struct {
 	Details string `json:"details,omitempty"`
 }
 
+// SessionInfo - сессия игрока, выданная при join.
+// С ее ID клиент может возобновить сессию после обрыва соединения в течение GracePeriod.
+type SessionInfo struct {
+	SessionID   string `json:"session_id"`
+	PlayerID    int    `json:"player_id"`
+	CharacterID int    `json:"character_id"`
+	GracePeriod int64  `json:"grace_period"` // Сколько сессия ждет переподключения (мс)
+	ServerTime  int64  `json:"server_time"`
+}
+
+// SessionResumed - результат возобновления сессии. Если FullState, пропущенные
+// сообщения уже недоступны и клиент получит полное world_state вместо них.
+type SessionResumed struct {
+	SessionID   string `json:"session_id"`
+	CharacterID int    `json:"character_id"`
+	LastSeq     int64  `json:"last_seq"` // Seq, с которого продолжается поток
+	Replayed    int    `json:"replayed"` // Сколько пропущенных сообщений будет отправлено повторно
+	FullState   bool   `json:"full_state,omitempty"`
+	ServerTime  int64  `json:"server_time"`
+}
+
+// Области видимости сообщений чата
+const (
+	ChatLocal   = "local"   // Игроки в той же локации
+	ChatGlobal  = "global"  // Все игроки на сервере
+	ChatWhisper = "whisper" // Один игрок
+	ChatEmote   = "emote"   // Эмоция, видна в той же локации
+)
+
+// ChatMessage - сообщение чата или эмоция
+type ChatMessage struct {
+	Scope         string `json:"scope"`
+	FromPlayer    int    `json:"from_player"`
+	FromCharacter int    `json:"from_character"`
+	FromName      string `json:"from_name"`
+	ToPlayer      int    `json:"to_player,omitempty"`   // Получатель whisper
+	LocationID    int    `json:"location_id,omitempty"` // Локация для local и emote
+	Text          string `json:"text"`
+	Emote         string `json:"emote,omitempty"`
+	ServerTime    int64  `json:"server_time"`
+}
+
+// CombatUpdate - удар одного участника боя по другому в локации
+type CombatUpdate struct {
+	LocationID   int    `json:"location_id"`
+	AttackerKind string `json:"attacker_kind"` // EntityCharacter или EntityCreature
+	AttackerID   int    `json:"attacker_id"`
+	TargetKind   string `json:"target_kind"`
+	TargetID     int    `json:"target_id"`
+	Damage       int    `json:"damage"`
+	Health       int    `json:"health"` // Здоровье цели после удара
+	MaxHealth    int    `json:"max_health"`
+	Killed       bool   `json:"killed,omitempty"`
+	ServerTime   int64  `json:"server_time"`
+}
+
+// CreatureDied - гибель существа
+type CreatureDied struct {
+	LocationID  int     `json:"location_id"`
+	CreatureID  int     `json:"creature_id"`
+	TypeID      int     `json:"type_id"`
+	Name        string  `json:"name"`
+	X           float64 `json:"x"`
+	KillerKind  string  `json:"killer_kind,omitempty"`
+	KillerID    int     `json:"killer_id,omitempty"`
+	ContainerID int     `json:"container_id,omitempty"` // Хранилище с добычей
+	ServerTime  int64   `json:"server_time"`
+}
+
+// CharacterDied - гибель персонажа; его вещи остаются в хранилище ContainerID
+type CharacterDied struct {
+	LocationID  int     `json:"location_id"`
+	CharacterID int     `json:"character_id"`
+	Name        string  `json:"name"`
+	X           float64 `json:"x"`
+	Cause       string  `json:"cause"` // character, creature, hunger, thirst
+	KillerID    int     `json:"killer_id,omitempty"`
+	ContainerID int     `json:"container_id,omitempty"`
+	ServerTime  int64   `json:"server_time"`
+}
+
+// ShutdownMessage - уведомление об остановке сервера
+type ShutdownMessage struct {
+	Reason     string `json:"reason"`
+	ServerTime int64  `json:"server_time"`
+}
+
 // Helper functions
 func Now() int64 {
 	return time.Now().UnixMilli()
@@ -3,6 +3,7 @@ package world
 import (
 	"LOIL-server/internal/config"
 	"encoding/json"
+	"fmt"
 	"os"
 )
 
@@ -13,6 +14,24 @@ func LoadWorld(filename string, configs *config.Configs) (*World, error) {
 		return nil, err
 	}
 
+	world, err := ParseWorld(data, configs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return world, nil
+}
+
+// ParseWorld разбирает мир из JSON (например, встроенного в бинарник).
+// Сохранения старых версий формата предварительно обновляются миграциями.
+func ParseWorld(data []byte, configs *config.Configs) (*World, error) {
+	data, version, err := MigrateWorld(data)
+	if err != nil {
+		return nil, err
+	}
+	if version != CurrentFormatVersion {
+		fmt.Printf("Формат мира обновлен с версии %d до %d\n", version, CurrentFormatVersion)
+	}
+
 	world := &World{}
 	if err := json.Unmarshal(data, world); err != nil {
 		return nil, err
@@ -46,12 +65,13 @@ func LoadWorld(filename string, configs *config.Configs) (*World, error) {
 	return world, nil
 }
 
-// SaveWorld сохраняет мир в файл
+// SaveWorld сохраняет мир в файл атомарно (через временный файл)
 func SaveWorld(world *World, filename string) error {
+	world.FormatVersion = CurrentFormatVersion
 	data, err := json.MarshalIndent(world, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filename, data, 0644)
+	return WriteFileAtomic(filename, data, 0644)
 }
@@ -0,0 +1,235 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CurrentFormatVersion - версия формата сохранения, которую пишет сервер.
+// При изменении формата увеличьте версию и зарегистрируйте миграцию с предыдущей.
+const CurrentFormatVersion = 2
+
+// LayerNames - имена слоев локации в JSON
+var LayerNames = []string{"foreground", "road", "ground", "background"}
+
+// Document - сохранение в виде разобранного JSON, с которым работают миграции.
+// Миграции меняют документ напрямую, поэтому могут переименовывать поля
+// и менять их кодирование (например, формат строк слоев).
+type Document map[string]interface{}
+
+// Migration - шаг обновления сохранения с версии From на From+1
+type Migration struct {
+	From        int
+	Description string
+	Migrate     func(doc Document) error
+}
+
+// migrations - зарегистрированные шаги по исходной версии
+var migrations = make(map[int]Migration)
+
+// RegisterMigration регистрирует шаг миграции. Шаги регистрируются при
+// инициализации пакета, поэтому повторная регистрация - ошибка программиста.
+func RegisterMigration(m Migration) {
+	if _, exists := migrations[m.From]; exists {
+		panic(fmt.Sprintf("миграция с версии %d уже зарегистрирована", m.From))
+	}
+	if m.From < 0 || m.From >= CurrentFormatVersion {
+		panic(fmt.Sprintf("миграция с версии %d вне диапазона 0..%d", m.From, CurrentFormatVersion-1))
+	}
+	migrations[m.From] = m
+}
+
+func init() {
+	RegisterMigration(Migration{
+		From:        0,
+		Description: "объекты локаций перенесены в общий список objects",
+		Migrate:     migrateLocationObjects,
+	})
+	RegisterMigration(Migration{
+		From:        1,
+		Description: "длительность поведения существ считается в тиках вместо времени начала",
+		Migrate:     migrateBehaviorTicks,
+	})
+}
+
+// MigrateWorld обновляет JSON сохранения до текущей версии формата.
+// Сохранения без format_version считаются версией 0.
+// Возвращает обновленный JSON и исходную версию.
+func MigrateWorld(data []byte) ([]byte, int, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	version, err := doc.formatVersion()
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > CurrentFormatVersion {
+		return nil, version, fmt.Errorf("версия формата %d новее поддерживаемой %d", version, CurrentFormatVersion)
+	}
+	if version == CurrentFormatVersion {
+		return data, version, nil
+	}
+
+	for v := version; v < CurrentFormatVersion; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return nil, version, fmt.Errorf("нет миграции с версии %d", v)
+		}
+		if err := m.Migrate(doc); err != nil {
+			return nil, version, fmt.Errorf("миграция с версии %d (%s): %w", v, m.Description, err)
+		}
+		doc["format_version"] = v + 1
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, err
+	}
+	return migrated, version, nil
+}
+
+// formatVersion возвращает версию формата документа (0, если поле отсутствует)
+func (doc Document) formatVersion() (int, error) {
+	raw, ok := doc["format_version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	version, ok := raw.(float64)
+	if !ok || version < 0 || version != float64(int(version)) {
+		return 0, fmt.Errorf("format_version: неверное значение %v", raw)
+	}
+	return int(version), nil
+}
+
+// Locations возвращает локации документа
+func (doc Document) Locations() []map[string]interface{} {
+	list, _ := doc["locations"].([]interface{})
+	locations := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if loc, ok := item.(map[string]interface{}); ok {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// MapLayers заменяет значение каждого слоя каждой локации результатом fn.
+// fn получает слой, разобранный из текущего кодирования, и возвращает новое значение для JSON.
+func (doc Document) MapLayers(fn func(name string, layer []int) (interface{}, error)) error {
+	for i, loc := range doc.Locations() {
+		for _, name := range LayerNames {
+			value, ok := loc[name]
+			if !ok {
+				continue
+			}
+			layer, err := DecodeLayer(value)
+			if err != nil {
+				return fmt.Errorf("locations[%d].%s: %w", i, name, err)
+			}
+			if loc[name], err = fn(name, layer); err != nil {
+				return fmt.Errorf("locations[%d].%s: %w", i, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DecodeLayer разбирает слой из строки чисел через пробел (текущее кодирование)
+// или из JSON массива чисел
+func DecodeLayer(value interface{}) ([]int, error) {
+	switch v := value.(type) {
+	case string:
+		parts := strings.Fields(v)
+		layer := make([]int, len(parts))
+		for i, part := range parts {
+			val, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка парсинга значения '%s': %v", part, err)
+			}
+			layer[i] = val
+		}
+		return layer, nil
+	case []interface{}:
+		layer := make([]int, len(v))
+		for i, item := range v {
+			val, ok := item.(float64)
+			if !ok || val != float64(int(val)) {
+				return nil, fmt.Errorf("неверное значение %v в позиции %d", item, i)
+			}
+			layer[i] = int(val)
+		}
+		return layer, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("неизвестное кодирование слоя %T", value)
+}
+
+// EncodeLayer кодирует слой в текущий формат (строка чисел через пробел)
+func EncodeLayer(layer []int) string {
+	parts := make([]string, len(layer))
+	for i, val := range layer {
+		parts[i] = strconv.Itoa(val)
+	}
+	return strings.Join(parts, " ")
+}
+
+// migrateLocationObjects переносит объекты из локаций в общий список objects.
+// В версии 0 объекты хранились и в локациях, и в общем списке; location_id
+// берется из локации, в которой лежал объект.
+func migrateLocationObjects(doc Document) error {
+	objects, _ := doc["objects"].(map[string]interface{})
+	if objects == nil {
+		objects = make(map[string]interface{})
+	}
+
+	for i, loc := range doc.Locations() {
+		locObjects, _ := loc["objects"].(map[string]interface{})
+
+		keys := make([]string, 0, len(locObjects))
+		for key := range locObjects {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			obj, ok := locObjects[key].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("locations[%d].objects.%s: объект должен быть JSON объектом", i, key)
+			}
+			if id, ok := loc["id"]; ok {
+				obj["location_id"] = id
+			}
+			objects[key] = obj
+		}
+
+		loc["objects"] = map[string]interface{}{}
+	}
+
+	doc["objects"] = objects
+	return nil
+}
+
+// migrateBehaviorTicks заменяет время начала поведения существ счетчиком тиков.
+// Реальное время начала не переводится в тики, поэтому поведение начинается заново.
+func migrateBehaviorTicks(doc Document) error {
+	creatures, _ := doc["creatures"].([]interface{})
+	for i, item := range creatures {
+		creature, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("creatures[%d]: существо должно быть JSON объектом", i)
+		}
+		behavior, ok := creature["behavior"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		delete(behavior, "start_time")
+		behavior["ticks"] = 0
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readFixture читает сохранение из testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("чтение %s: %v", name, err)
+	}
+	return data
+}
+
+// checkBehaviorTicks проверяет, что после миграции у поведения существ есть ticks и нет start_time
+func checkBehaviorTicks(t *testing.T, migrated []byte) {
+	t.Helper()
+
+	var doc struct {
+		Creatures []struct {
+			ID       int                    `json:"id"`
+			Behavior map[string]interface{} `json:"behavior"`
+		} `json:"creatures"`
+	}
+	if err := json.Unmarshal(migrated, &doc); err != nil {
+		t.Fatalf("разбор результата миграции: %v", err)
+	}
+
+	for _, creature := range doc.Creatures {
+		if creature.Behavior == nil {
+			continue
+		}
+		if ticks, ok := creature.Behavior["ticks"]; !ok || ticks != float64(0) {
+			t.Errorf("существо %d: behavior.ticks = %v, ожидали 0", creature.ID, ticks)
+		}
+		if _, ok := creature.Behavior["start_time"]; ok {
+			t.Errorf("существо %d: behavior.start_time не удален", creature.ID)
+		}
+	}
+}
+
+func TestParseWorldVersion0(t *testing.T) {
+	data := readFixture(t, "world_v0.json")
+
+	migrated, version, err := MigrateWorld(data)
+	if err != nil {
+		t.Fatalf("MigrateWorld: %v", err)
+	}
+	if version != 0 {
+		t.Errorf("исходная версия %d, ожидали 0", version)
+	}
+	checkBehaviorTicks(t, migrated)
+
+	world, err := ParseWorld(data, nil)
+	if err != nil {
+		t.Fatalf("ParseWorld: %v", err)
+	}
+	if world.FormatVersion != CurrentFormatVersion {
+		t.Errorf("format_version = %d, ожидали %d", world.FormatVersion, CurrentFormatVersion)
+	}
+
+	// Объекты переехали из локаций в общий список, location_id взят из локации
+	wantLocations := map[int]int{100: 1, 200: 2}
+	if len(world.Objects) != len(wantLocations) {
+		t.Errorf("объектов %d, ожидали %d", len(world.Objects), len(wantLocations))
+	}
+	for id, locationID := range wantLocations {
+		obj, ok := world.Objects[id]
+		if !ok {
+			t.Errorf("объект %d не найден в общем списке", id)
+			continue
+		}
+		if obj.LocationID != locationID {
+			t.Errorf("объект %d: location_id = %d, ожидали %d", id, obj.LocationID, locationID)
+		}
+	}
+	for _, loc := range world.Locations {
+		if len(loc.Objects) != 0 {
+			t.Errorf("в локации %d остались объекты: %v", loc.ID, loc.Objects)
+		}
+	}
+
+	creature := world.Creatures[0]
+	if creature.CurrentBehavior == nil || creature.CurrentBehavior.Type != "rest" || creature.CurrentBehavior.Ticks != 0 {
+		t.Errorf("поведение существа после миграции: %+v", creature.CurrentBehavior)
+	}
+}
+
+func TestParseWorldVersion1(t *testing.T) {
+	data := readFixture(t, "world_v1.json")
+
+	migrated, version, err := MigrateWorld(data)
+	if err != nil {
+		t.Fatalf("MigrateWorld: %v", err)
+	}
+	if version != 1 {
+		t.Errorf("исходная версия %d, ожидали 1", version)
+	}
+	checkBehaviorTicks(t, migrated)
+
+	world, err := ParseWorld(data, nil)
+	if err != nil {
+		t.Fatalf("ParseWorld: %v", err)
+	}
+	if world.FormatVersion != CurrentFormatVersion {
+		t.Errorf("format_version = %d, ожидали %d", world.FormatVersion, CurrentFormatVersion)
+	}
+	if obj := world.Objects[100]; obj == nil || obj.LocationID != 1 {
+		t.Errorf("объект 100 после миграции: %+v", obj)
+	}
+
+	if len(world.Creatures) != 2 {
+		t.Fatalf("существ %d, ожидали 2", len(world.Creatures))
+	}
+	behavior := world.Creatures[0].CurrentBehavior
+	if behavior == nil || behavior.Type != "wander" || behavior.Duration != 4.5 || behavior.Ticks != 0 {
+		t.Errorf("поведение существа после миграции: %+v", behavior)
+	}
+	if world.Creatures[1].CurrentBehavior != nil {
+		t.Errorf("существо без поведения получило поведение: %+v", world.Creatures[1].CurrentBehavior)
+	}
+}
+
+func TestParseWorldCurrentVersionUnchanged(t *testing.T) {
+	data := []byte(fmt.Sprintf(`{"format_version": %d, "locations": []}`, CurrentFormatVersion))
+
+	migrated, version, err := MigrateWorld(data)
+	if err != nil {
+		t.Fatalf("MigrateWorld: %v", err)
+	}
+	if version != CurrentFormatVersion {
+		t.Errorf("версия %d, ожидали %d", version, CurrentFormatVersion)
+	}
+	if string(migrated) != string(data) {
+		t.Errorf("сохранение текущей версии изменено миграцией: %s", migrated)
+	}
+}
+
+func TestMigrateWorldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string // Фрагмент ожидаемой ошибки
+	}{
+		{
+			name: "версия новее поддерживаемой",
+			data: fmt.Sprintf(`{"format_version": %d}`, CurrentFormatVersion+1),
+			want: "новее поддерживаемой",
+		},
+		{
+			name: "дробная версия",
+			data: `{"format_version": 1.5}`,
+			want: "format_version",
+		},
+		{
+			name: "отрицательная версия",
+			data: `{"format_version": -1}`,
+			want: "format_version",
+		},
+		{
+			name: "версия строкой",
+			data: `{"format_version": "2"}`,
+			want: "format_version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWorld([]byte(tt.data), nil)
+			if err == nil {
+				t.Fatal("ожидали ошибку")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ошибка %q не содержит %q", err, tt.want)
+			}
+		})
+	}
+}
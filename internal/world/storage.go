@@ -0,0 +1,145 @@
+package world
+
+import (
+	"LOIL-server/internal/config"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic записывает данные во временный файл в том же каталоге,
+// сбрасывает его на диск и переименовывает поверх filename.
+// При сбое во время записи старый файл остается нетронутым.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	// Удаляем временный файл, если что-то пошло не так
+	ok := false
+	defer func() {
+		if !ok {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return err
+	}
+	ok = true
+
+	// Сбрасываем каталог, чтобы переименование пережило сбой питания
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// backupName возвращает имя n-й резервной копии (world.json.1, world.json.2, ...)
+func backupName(filename string, n int) string {
+	return fmt.Sprintf("%s.%d", filename, n)
+}
+
+// RotateBackups сдвигает резервные копии (file.1 -> file.2, ...) и сохраняет
+// текущий файл как file.1. Хранится не больше keep копий.
+func RotateBackups(filename string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	if _, err := os.Stat(filename); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	// Самая старая копия удаляется
+	if err := os.Remove(backupName(filename, keep)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for n := keep - 1; n >= 1; n-- {
+		if err := os.Rename(backupName(filename, n), backupName(filename, n+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	// Текущий файл остается на месте до атомарной замены, поэтому копию делаем жесткой ссылкой
+	if err := os.Link(filename, backupName(filename, 1)); err == nil {
+		return nil
+	}
+	return copyFile(filename, backupName(filename, 1))
+}
+
+// copyFile копирует файл (если жесткие ссылки не поддерживаются)
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// LoadSave загружает сохранение из файла, а если оно повреждено - самую свежую
+// читаемую резервную копию. Возвращает мир и имя загруженного файла.
+// Если сохранения нет, возвращается ошибка fs.ErrNotExist.
+func LoadSave(filename string, configs *config.Configs) (*World, string, error) {
+	world, err := LoadWorld(filename, configs)
+	if err == nil {
+		return world, filename, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, filename, err
+	}
+	fmt.Printf("Не удалось загрузить сохранение: %v\n", err)
+
+	for n := 1; ; n++ {
+		backup := backupName(filename, n)
+		if _, statErr := os.Stat(backup); statErr != nil {
+			break
+		}
+
+		world, backupErr := LoadWorld(backup, configs)
+		if backupErr == nil {
+			fmt.Printf("Загружена резервная копия %s\n", backup)
+			return world, backup, nil
+		}
+		fmt.Printf("Не удалось загрузить резервную копию: %v\n", backupErr)
+	}
+
+	return nil, filename, err
+}
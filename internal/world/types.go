@@ -25,23 +25,18 @@ func (is IntSlice) MarshalJSON() ([]byte, error) {
 	return []byte(sb.String()), nil
 }
 
-// UnmarshalJSON преобразует строку из JSON в IntSlice
+// UnmarshalJSON преобразует строку (или массив чисел) из JSON в IntSlice
 func (is *IntSlice) UnmarshalJSON(data []byte) error {
-	var str string
-	if err := json.Unmarshal(data, &str); err != nil {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
 		return err
 	}
 
-	parts := strings.Fields(str)
-	*is = make(IntSlice, len(parts))
-
-	for i, part := range parts {
-		var val int
-		if _, err := fmt.Sscanf(part, "%d", &val); err != nil {
-			return fmt.Errorf("ошибка парсинга значения '%s': %v", part, err)
-		}
-		(*is)[i] = val
+	layer, err := DecodeLayer(value)
+	if err != nil {
+		return err
 	}
+	*is = layer
 
 	return nil
 }
@@ -55,6 +50,7 @@ type WorldObject struct {
 	LocationID  int                    `json:"location_id"`  // ID локации
 	Durability  int                    `json:"durability"`   // Текущая прочность
 	GrowthStage int                    `json:"growth_stage"` // Стадия роста (0-100)
+	GrowthTime  float64                `json:"growth_time"`  // Сколько секунд объект уже растет
 	Storage     map[int]int            `json:"storage"`      // ID предмета -> количество
 	CustomData  map[string]interface{} `json:"custom_data"`  // Дополнительные данные
 }
@@ -72,12 +68,30 @@ type Character struct {
 	Location   int                   `json:"location"`
 	X          float64               `json:"x"`
 	Speed      float64               `json:"speed"`
+	Health     int                   `json:"health"`
+	MaxHealth  int                   `json:"max_health"`
+	Hunger     float64               `json:"hunger"`  // 0 - сыт, 100 - истощен
+	Thirst     float64               `json:"thirst"`  // 0 - напоен, 100 - обезвожен
+	Stamina    float64               `json:"stamina"` // Выносливость, 0 - персонаж устал и идет медленнее
 	Direction  int                   `json:"direction"`
 	Controlled int                   `json:"controlled"`
+	Owner      int                   `json:"owner,omitempty"` // ID игрока-владельца (0 - персонаж еще никому не принадлежит)
 	Vertical   int                   `json:"-"`
-	Inventory  map[int]InventoryItem `json:"inventory"`  // ID слота -> предмет
-	Equipped   map[string]int        `json:"equipped"`   // Тип инструмента -> item_id
-	HandsFree  bool                  `json:"hands_free"` // Руки свободны
+	Inventory  map[int]InventoryItem `json:"inventory"`        // ID слота -> предмет
+	Equipped   map[string]int        `json:"equipped"`         // Тип инструмента -> item_id
+	HandsFree  bool                  `json:"hands_free"`       // Руки свободны
+	Action     *CharacterAction      `json:"action,omitempty"` // Текущее длительное действие
+	AttackTick uint64                `json:"-"`                // Тик последней атаки персонажа
+}
+
+// CharacterAction - длительное действие персонажа (например, рубка дерева)
+type CharacterAction struct {
+	Type             string  `json:"type"`              // Тип взаимодействия (chop, mine, ...)
+	ObjectID         int     `json:"object_id"`         // Целевой объект
+	InteractionIndex int     `json:"interaction_index"` // Индекс взаимодействия в конфиге объекта
+	Duration         float64 `json:"duration"`          // Длительность в секундах
+	Elapsed          float64 `json:"elapsed"`           // Прошло секунд
+	LastReport       float64 `json:"-"`                 // Когда последний раз сообщали о прогрессе
 }
 
 // Location - локация мира
@@ -100,12 +114,13 @@ type Transition struct {
 
 // Обновим структуру CreatureBehavior
 type CreatureBehavior struct {
-	Type             string    `json:"type"`                // wander, eat, rest, attack, flee
-	TargetPos        int       `json:"target_pos"`          // Целевая позиция
-	Duration         float64   `json:"duration"`            // Длительность поведения в секундах
-	StartTime        time.Time `json:"start_time"`          // Время начала поведения
-	Cooldown         float64   `json:"cooldown"`            // Время перезарядки
-	AteAtCurrentStop bool      `json:"ate_at_current_stop"` // Уже поел на этой остановке
+	Type             string  `json:"type"`                // wander, eat, rest, attack, flee
+	TargetPos        int     `json:"target_pos"`          // Целевая позиция
+	Duration         float64 `json:"duration"`            // Длительность поведения в секундах
+	TargetID         int     `json:"target_id,omitempty"` // Персонаж, на которого направлено поведение (attack, flee)
+	Ticks            int     `json:"ticks"`               // Сколько тиков поведение уже выполняется
+	Cooldown         float64 `json:"cooldown"`            // Время перезарядки
+	AteAtCurrentStop bool    `json:"ate_at_current_stop"` // Уже поел на этой остановке
 }
 
 // Creature - существо (NPC)
@@ -134,10 +149,13 @@ type Interaction struct {
 
 // Обновим структуру World
 type World struct {
-	PlayerID   int                  `json:"player_id"`
-	Characters []*Character         `json:"characters"`
-	Locations  []*Location          `json:"locations"`
-	Objects    map[int]*WorldObject `json:"objects"`   // Все объекты мира
-	Creatures  []*Creature          `json:"creatures"` // Все существа мира
-	Configs    *config.Configs      `json:"-"`         // Конфигурации (не сериализуется в JSON)
+	FormatVersion int                  `json:"format_version"` // Версия формата сохранения
+	PlayerID      int                  `json:"player_id"`
+	Characters    []*Character         `json:"characters"`
+	Locations     []*Location          `json:"locations"`
+	Objects       map[int]*WorldObject `json:"objects"`   // Все объекты мира
+	Creatures     []*Creature          `json:"creatures"` // Все существа мира
+	SavedAt       time.Time            `json:"saved_at"`  // Время сохранения (для учета времени, пока сервер не работал)
+	Tick          uint64               `json:"tick"`      // Номер последнего тика симуляции
+	Configs       *config.Configs      `json:"-"`         // Конфигурации (не сериализуется в JSON)
 }
@@ -0,0 +1,272 @@
+package world
+
+import (
+	"LOIL-server/internal/config"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// knownTransitions - допустимые стороны переходов между локациями
+var knownTransitions = []string{"left_up", "left_down", "right_up", "right_down"}
+
+// Validate проверяет мир и его ссылки на конфигурации.
+// Ошибки добавляются в report с путем внутри файла filename.
+func Validate(world *World, filename string, configs *config.Configs, report *config.ValidationReport) {
+	v := &worldValidator{
+		world:     world,
+		file:      filename,
+		configs:   configs,
+		report:    report,
+		locations: make(map[int]*Location),
+		objectIDs: make(map[int]string),
+		objects:   make(map[*WorldObject]bool),
+	}
+
+	v.validateLocations()
+	v.validateObjects()
+	v.validateCharacters()
+	v.validateCreatures()
+}
+
+// worldValidator - состояние одной проверки мира
+type worldValidator struct {
+	world     *World
+	file      string
+	configs   *config.Configs
+	report    *config.ValidationReport
+	locations map[int]*Location     // Локации по ID
+	objectIDs map[int]string        // ID объекта -> путь, где он объявлен
+	objects   map[*WorldObject]bool // Уже проверенные объекты
+}
+
+func (v *worldValidator) addf(path, format string, args ...interface{}) {
+	v.report.Addf(v.file, path, format, args...)
+}
+
+func (v *worldValidator) validateLocations() {
+	// Сначала собираем ID, чтобы проверять переходы вперед по списку
+	for i, loc := range v.world.Locations {
+		path := fmt.Sprintf("locations[%d]", i)
+		if loc == nil {
+			v.addf(path, "пустое описание локации")
+			continue
+		}
+		if loc.ID <= 0 {
+			v.addf(path+".id", "ID должен быть положительным, получено %d", loc.ID)
+			continue
+		}
+		if _, ok := v.locations[loc.ID]; ok {
+			v.addf(path+".id", "локация с ID %d уже объявлена", loc.ID)
+			continue
+		}
+		v.locations[loc.ID] = loc
+	}
+
+	for i, loc := range v.world.Locations {
+		if loc == nil {
+			continue
+		}
+		path := fmt.Sprintf("locations[%d]", i)
+
+		// Все слои должны быть одной длины
+		if len(loc.Road) == 0 {
+			v.addf(path+".road", "слой дороги пуст")
+		}
+		layers := []struct {
+			name  string
+			layer IntSlice
+		}{
+			{"foreground", loc.Foreground},
+			{"road", loc.Road},
+			{"ground", loc.Ground},
+			{"background", loc.Background},
+		}
+		for _, l := range layers {
+			if len(l.layer) != len(loc.Road) {
+				v.addf(path+"."+l.name, "длина слоя %d не совпадает с длиной дороги %d", len(l.layer), len(loc.Road))
+			}
+		}
+
+		// Значения слоев должны ссылаться на существующие типы (0 - пусто)
+		for pos, id := range loc.Foreground {
+			if id != 0 && !v.configs.HasObjectType(id) {
+				v.addf(fmt.Sprintf("%s.foreground[%d]", path, pos), "тип объекта %d не найден", id)
+			}
+		}
+		for pos, id := range loc.Road {
+			if id != 0 && !v.configs.HasRoadType(id) {
+				v.addf(fmt.Sprintf("%s.road[%d]", path, pos), "тип дороги %d не найден", id)
+			}
+		}
+		for pos, id := range loc.Ground {
+			if id != 0 && !v.configs.HasGroundType(id) {
+				v.addf(fmt.Sprintf("%s.ground[%d]", path, pos), "тип земли %d не найден", id)
+			}
+		}
+		for pos, id := range loc.Background {
+			if id != 0 && !v.configs.HasObjectType(id) {
+				v.addf(fmt.Sprintf("%s.background[%d]", path, pos), "тип объекта %d не найден", id)
+			}
+		}
+
+		for _, side := range sortedKeys(loc.Transitions) {
+			trans := loc.Transitions[side]
+			transPath := fmt.Sprintf("%s.transitions.%s", path, side)
+			if !slices.Contains(knownTransitions, side) {
+				v.addf(transPath, "неизвестная сторона перехода")
+			}
+			if trans == nil {
+				continue
+			}
+			if _, ok := v.locations[trans.LocationID]; !ok {
+				v.addf(transPath+".location_id", "локация %d не найдена", trans.LocationID)
+			}
+		}
+	}
+}
+
+func (v *worldValidator) validateObjects() {
+	for i, loc := range v.world.Locations {
+		if loc == nil {
+			continue
+		}
+		for _, key := range sortedKeys(loc.Objects) {
+			path := fmt.Sprintf("locations[%d].objects.%d", i, key)
+			obj := loc.Objects[key]
+			if obj != nil && obj.LocationID != loc.ID {
+				v.addf(path+".location_id", "объект лежит в локации %d, но указана локация %d", loc.ID, obj.LocationID)
+			}
+			v.validateObject(path, key, obj)
+		}
+	}
+
+	// LoadWorld добавляет объекты локаций в общий список - их уже проверили
+	for _, key := range sortedKeys(v.world.Objects) {
+		if obj := v.world.Objects[key]; obj == nil || !v.objects[obj] {
+			v.validateObject(fmt.Sprintf("objects.%d", key), key, obj)
+		}
+	}
+}
+
+func (v *worldValidator) validateObject(path string, key int, obj *WorldObject) {
+	if obj == nil {
+		v.addf(path, "пустое описание объекта")
+		return
+	}
+	v.objects[obj] = true
+
+	if obj.ID != key {
+		v.addf(path+".id", "ID %d не совпадает с ключом %d", obj.ID, key)
+	}
+	if other, ok := v.objectIDs[obj.ID]; ok {
+		v.addf(path+".id", "объект с ID %d уже объявлен в %s", obj.ID, other)
+	} else {
+		v.objectIDs[obj.ID] = path
+	}
+
+	if !v.configs.HasObjectType(obj.TypeID) {
+		v.addf(path+".type_id", "тип объекта %d не найден", obj.TypeID)
+	}
+	if obj.GrowthStage < 0 || obj.GrowthStage > 100 {
+		v.addf(path+".growth_stage", "стадия роста должна быть от 0 до 100, получено %d", obj.GrowthStage)
+	}
+	v.checkPosition(path, obj.LocationID, float64(obj.X), "location_id")
+
+	for _, itemID := range sortedKeys(obj.Storage) {
+		if !v.configs.HasItemType(itemID) {
+			v.addf(fmt.Sprintf("%s.storage.%d", path, itemID), "тип предмета %d не найден", itemID)
+		}
+	}
+}
+
+func (v *worldValidator) validateCharacters() {
+	ids := make(map[int]bool)
+	for i, char := range v.world.Characters {
+		path := fmt.Sprintf("characters[%d]", i)
+		if char == nil {
+			v.addf(path, "пустое описание персонажа")
+			continue
+		}
+
+		if char.ID <= 0 {
+			v.addf(path+".id", "ID должен быть положительным, получено %d", char.ID)
+		} else if ids[char.ID] {
+			v.addf(path+".id", "персонаж с ID %d уже объявлен", char.ID)
+		}
+		ids[char.ID] = true
+
+		v.checkPosition(path, char.Location, char.X, "location")
+		v.checkInventory(path+".inventory", char.Inventory)
+
+		for _, tool := range sortedKeys(char.Equipped) {
+			if itemID := char.Equipped[tool]; !v.configs.HasItemType(itemID) {
+				v.addf(fmt.Sprintf("%s.equipped.%s", path, tool), "тип предмета %d не найден", itemID)
+			}
+		}
+	}
+}
+
+func (v *worldValidator) validateCreatures() {
+	ids := make(map[int]bool)
+	for i, creature := range v.world.Creatures {
+		path := fmt.Sprintf("creatures[%d]", i)
+		if creature == nil {
+			v.addf(path, "пустое описание существа")
+			continue
+		}
+
+		if creature.ID <= 0 {
+			v.addf(path+".id", "ID должен быть положительным, получено %d", creature.ID)
+		} else if ids[creature.ID] {
+			v.addf(path+".id", "существо с ID %d уже объявлено", creature.ID)
+		}
+		ids[creature.ID] = true
+
+		if !v.configs.HasCreatureType(creature.TypeID) {
+			v.addf(path+".type_id", "тип существа %d не найден", creature.TypeID)
+		}
+		if creature.MaxHealth > 0 && creature.Health > creature.MaxHealth {
+			v.addf(path+".health", "здоровье %d больше максимального %d", creature.Health, creature.MaxHealth)
+		}
+
+		v.checkPosition(path, creature.Location, creature.X, "location")
+		v.checkInventory(path+".inventory", creature.Inventory)
+	}
+}
+
+// checkPosition проверяет, что локация существует и позиция лежит в ее пределах
+func (v *worldValidator) checkPosition(path string, locationID int, x float64, locationField string) {
+	loc, ok := v.locations[locationID]
+	if !ok {
+		v.addf(path+"."+locationField, "локация %d не найдена", locationID)
+		return
+	}
+	if x < 0 || int(x+0.5) >= len(loc.Road) {
+		v.addf(path+".x", "позиция %g вне локации %d (длина %d)", x, locationID, len(loc.Road))
+	}
+}
+
+// checkInventory проверяет предметы инвентаря
+func (v *worldValidator) checkInventory(path string, inventory map[int]InventoryItem) {
+	for _, slot := range sortedKeys(inventory) {
+		item := inventory[slot]
+		slotPath := fmt.Sprintf("%s.%d", path, slot)
+		if !v.configs.HasItemType(item.ItemID) {
+			v.addf(slotPath+".item_id", "тип предмета %d не найден", item.ItemID)
+		}
+		if item.Count <= 0 {
+			v.addf(slotPath+".count", "количество должно быть положительным, получено %d", item.Count)
+		}
+	}
+}
+
+// sortedKeys возвращает ключи map в отсортированном порядке (для стабильного отчета)
+func sortedKeys[K int | string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}